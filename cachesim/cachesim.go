@@ -0,0 +1,203 @@
+// Package cachesim simulates set-associative LRU caches and a TLB,
+// so that a trace of memory addresses, the nodes a mergesort reads
+// and writes for instance, can be turned into cache and TLB miss
+// counts on machines that don't expose hardware counters.
+package cachesim
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Cache is one level of set-associative cache, with least recently
+// used replacement. A TLB is a Cache whose lines are pages.
+type Cache struct {
+	Name     string
+	Size     int // bytes
+	LineSize int // bytes
+	Ways     int
+	Accesses int
+	Misses   int
+
+	lineShift int
+	sets      int
+	tags      []uint64 // each set's tags, most recently used first, 0 is empty
+}
+
+// NewCache creates an empty cache of size bytes, made of lineSize
+// byte lines, ways lines per set. lineSize has to be a power of 2,
+// and size a multiple of lineSize*ways.
+func NewCache(name string, size, lineSize, ways int) (*Cache, error) {
+	if lineSize < 1 || lineSize&(lineSize-1) != 0 {
+		return nil, fmt.Errorf("%s: line size %d isn't a power of 2", name, lineSize)
+	}
+	if ways < 1 || size < lineSize*ways || size%(lineSize*ways) != 0 {
+		return nil, fmt.Errorf("%s: size %d isn't a multiple of %d %d-byte ways", name, size, ways, lineSize)
+	}
+	sets := size / (lineSize * ways)
+	return &Cache{
+		Name:      name,
+		Size:      size,
+		LineSize:  lineSize,
+		Ways:      ways,
+		lineShift: bits.TrailingZeros(uint(lineSize)),
+		sets:      sets,
+		tags:      make([]uint64, sets*ways),
+	}, nil
+}
+
+// Access looks up the line holding addr, making it the most recently
+// used line of its set, and reports whether it was already cached.
+// A miss replaces the set's least recently used line.
+func (c *Cache) Access(addr uintptr) bool {
+	c.Accesses++
+	line := uint64(addr) >> c.lineShift
+	set := int(line % uint64(c.sets))
+	tags := c.tags[set*c.Ways : (set+1)*c.Ways]
+	tag := line + 1 // 0 marks an empty way
+
+	for i, t := range tags {
+		if t == tag {
+			copy(tags[1:i+1], tags[:i])
+			tags[0] = tag
+			return true
+		}
+	}
+
+	c.Misses++
+	copy(tags[1:], tags[:len(tags)-1])
+	tags[0] = tag
+	return false
+}
+
+// Clear empties the cache, without changing Accesses or Misses.
+func (c *Cache) Clear() {
+	clear(c.tags)
+}
+
+// String describes the cache's geometry.
+func (c *Cache) String() string {
+	return fmt.Sprintf("%s %s, %d-byte lines, %d-way", c.Name, FormatSize(c.Size), c.LineSize, c.Ways)
+}
+
+// Hierarchy is levels of cache, L1 first, and an optional TLB.
+// Every access goes to the TLB and to L1, and an access that misses
+// in one level goes on to the next. Lower levels don't hold
+// a copy of everything in higher levels, and reads and writes
+// are the same, so there's no write-back traffic.
+type Hierarchy struct {
+	Levels []*Cache
+	TLB    *Cache
+}
+
+// Access simulates one memory access at addr.
+func (h *Hierarchy) Access(addr uintptr) {
+	if h.TLB != nil {
+		h.TLB.Access(addr)
+	}
+	for _, c := range h.Levels {
+		if c.Access(addr) {
+			break
+		}
+	}
+}
+
+// Reset empties every cache and the TLB, and zeroes their counts.
+func (h *Hierarchy) Reset() {
+	for _, c := range h.Levels {
+		c.reset()
+	}
+	if h.TLB != nil {
+		h.TLB.reset()
+	}
+}
+
+func (c *Cache) reset() {
+	c.Clear()
+	c.Accesses = 0
+	c.Misses = 0
+}
+
+// ParseCache creates a cache from a "size:line:ways" spec,
+// "32K:64:8" for example. Sizes can have any suffix ParseSize takes.
+func ParseCache(name, spec string) (*Cache, error) {
+	n, err := parseSpec(name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return NewCache(name, n[0], n[1], n[2])
+}
+
+// ParseTLB creates a TLB from an "entries:ways:pagesize" spec,
+// "64:4:4K" for example.
+func ParseTLB(name, spec string) (*Cache, error) {
+	n, err := parseSpec(name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return NewCache(name, n[0]*n[2], n[2], n[1])
+}
+
+func parseSpec(name, spec string) ([3]int, error) {
+	var n [3]int
+	fields := strings.Split(spec, ":")
+	if len(fields) != 3 {
+		return n, fmt.Errorf("%s: %q doesn't have 3 colon-separated fields", name, spec)
+	}
+	for i, f := range fields {
+		v, err := ParseSize(f)
+		if err != nil {
+			return n, fmt.Errorf("%s: %w", name, err)
+		}
+		n[i] = v
+	}
+	return n, nil
+}
+
+// ParseSize converts a decimal number with an optional K, M or G
+// suffix, any case, to a number of bytes. K is 1024, M 1024K and
+// G 1024M. The suffix can end in B or iB, so 32K, 32k, 32KB and
+// 32KiB are all 32768, and a plain B is bytes.
+func ParseSize(s string) (int, error) {
+	digits, shift := strings.ToLower(s), 0
+	for _, u := range sizeSuffixes {
+		if d, ok := strings.CutSuffix(digits, u.suffix); ok {
+			digits, shift = d, u.shift
+			break
+		}
+	}
+	v, err := strconv.Atoi(digits)
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("size %q has to be a positive number of bytes, with an optional K, M or G suffix, "+
+			"any case, maybe followed by B or iB: 32768, 32K, 32k, 32KB or 32KiB", s)
+	}
+	return v << shift, nil
+}
+
+// sizeSuffixes are the suffixes ParseSize knows, in lower case,
+// longest first where one ends another.
+var sizeSuffixes = []struct {
+	suffix string
+	shift  int
+}{
+	{"kib", 10}, {"kb", 10}, {"k", 10},
+	{"mib", 20}, {"mb", 20}, {"m", 20},
+	{"gib", 30}, {"gb", 30}, {"g", 30},
+	{"b", 0},
+}
+
+// FormatSize is the inverse of ParseSize, using the biggest
+// suffix that leaves a whole number.
+func FormatSize(v int) string {
+	for _, u := range []struct {
+		shift  int
+		suffix string
+	}{{30, "G"}, {20, "M"}, {10, "K"}} {
+		if v >= 1<<u.shift && v%(1<<u.shift) == 0 {
+			return strconv.Itoa(v>>u.shift) + u.suffix
+		}
+	}
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,231 @@
+package main
+
+/*
+ * Simulate cache and TLB misses of the listsort mergesorts,
+ * feeding every node read and write to a model of
+ * set-associative LRU caches and a TLB
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unsafe"
+
+	"mergesort/cachesim"
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/listsort/observed"
+	"mergesort/prng"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+// caches gets the address of every node the sorts read or write
+var caches cachesim.Hierarchy
+
+// toucher is the observed.Observer that gives caches the address
+// of every node a sort reads or writes. A node's .Data and .Next
+// fields are always in the same cache line and page, and a second
+// access to the node with nothing in between would hit in a register,
+// so it only touches the node the first time.
+type toucher struct {
+	observed.NopObserver
+	last *Node
+}
+
+func (t *toucher) Read(node *Node)  { t.touch(node) }
+func (t *toucher) Write(node *Node) { t.touch(node) }
+
+func (t *toucher) touch(node *Node) {
+	if node == t.last {
+		return
+	}
+	t.last = node
+	caches.Access(uintptr(unsafe.Pointer(node)))
+}
+
+func main() {
+	algorithms := flag.String("a", "all", "comma separated algorithms to simulate, or all: "+strings.Join(observed.Names, ", "))
+	minGallop := flag.Int("W", 7, "galloping merges gallop after this many wins in a row")
+	parallelDepth := flag.Int("d", 3, "parallel mergesort recursion depth using goroutines")
+	k := flag.Int("k", 4, "number of sublists the k-way mergesort merges")
+
+	l1Spec := flag.String("L1", "32K:64:8", "L1 cache size:line:ways, empty for none")
+	l2Spec := flag.String("L2", "1M:64:16", "L2 cache size:line:ways, empty for none")
+	l3Spec := flag.String("L3", "32M:64:16", "L3 cache size:line:ways, empty for none")
+	tlbSpec := flag.String("TLB", "64:4:4K", "TLB entries:ways:pagesize, empty for none")
+
+	alreadySorted := flag.Bool("s", false, "already sorted low-to-high list")
+	reverseSorted := flag.Bool("S", false, "reverse sorted high-to-low list")
+	distribution := flag.String("v", "", "data value distribution: "+listgen.Distributions)
+	addressOrderedList := flag.Bool("m", false, "create address-ordered list for each sort")
+
+	countIncrement := flag.Int("i", 100000, "increment of list size")
+	countBegin := flag.Int("b", 1000, "beginning list size")
+	countUntil := flag.Int("u", 1000000, "sort lists up to this size")
+
+	iterations := flag.Int("I", 1, "number of sorts conducted at any given list length")
+
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
+	flag.Parse()
+
+	names, err := observed.Select(*algorithms)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := observed.Options{MinGallop: *minGallop, Depth: *parallelDepth, K: *k}
+	sorts := make([]func(*Node, observed.Observer) *Node, len(names))
+	for i, name := range names {
+		if sorts[i], err = observed.Lookup(name, opts); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, l := range []struct{ name, spec string }{{"L1", *l1Spec}, {"L2", *l2Spec}, {"L3", *l3Spec}} {
+		if l.spec == "" {
+			continue
+		}
+		c, err := cachesim.ParseCache(l.name, l.spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		caches.Levels = append(caches.Levels, c)
+	}
+	if *tlbSpec != "" {
+		var err error
+		if caches.TLB, err = cachesim.ParseTLB("TLB", *tlbSpec); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
+
+	hostname, _ := os.Hostname() // not going to fail
+
+	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
+	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
+		*countBegin, *countUntil, *countIncrement)
+	fmt.Printf("# %d iterations of a given list length\n", *iterations)
+
+	listType := "idiomatic"
+	if *addressOrderedList {
+		listType = "memory address"
+	}
+	fmt.Printf("# %s list in-memory ordering\n", listType)
+	fmt.Printf("# %s random numbers as list node values\n", *prngName)
+	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
+
+	var listCreation func(int, bool) *Node
+	listCreation = listgen.RandomValueList
+	listCreationPhrase := "randomly chosen data"
+	if *addressOrderedList {
+		listCreation = listgen.MemoryOrderedList
+		listCreationPhrase = "unordered"
+		fmt.Printf("# node addresses ascending in memory\n")
+	}
+	if *alreadySorted {
+		listCreation = listgen.PresortedList
+		listCreationPhrase = "presorted"
+	}
+	if *reverseSorted {
+		listCreation = listgen.ReverseSortedList
+		listCreationPhrase = "reverse sorted"
+	}
+	if *distribution != "" {
+		if *alreadySorted || *reverseSorted || *addressOrderedList {
+			log.Fatalf("-v doesn't allow -s, -S or -m\n")
+		}
+		var err error
+		if listCreation, listCreationPhrase, err = listgen.Distribution(*distribution); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Printf("# %s data values\n", listCreationPhrase)
+
+	columns := []string{"accesses"}
+	for _, c := range caches.Levels {
+		fmt.Printf("# %s\n", c)
+		columns = append(columns, c.Name+" misses")
+	}
+	if caches.TLB != nil {
+		t := caches.TLB
+		fmt.Printf("# TLB %d entries, %s pages, %d-way\n", t.Size/t.LineSize, cachesim.FormatSize(t.LineSize), t.Ways)
+		columns = append(columns, "TLB misses")
+	}
+	fmt.Printf("# galloping merges gallop after %d wins in a row, parallel depth %d, k-way k %d\n",
+		*minGallop, *parallelDepth, *k)
+	fmt.Printf("# size, then %s for each of %s\n", strings.Join(columns, ", "), strings.Join(names, ", "))
+
+	for n := *countBegin; n < *countUntil; n += *countIncrement {
+
+		totals := make([][]int, len(sorts))
+		for i := range totals {
+			totals[i] = make([]int, len(columns))
+		}
+
+		for j := 0; j < *iterations; j++ {
+			head := listCreation(n, true)
+			// original order of nodes restorable
+			order := listgen.NodeOrder(head)
+
+			for i, sort := range sorts {
+				// every sort starts with empty caches
+				caches.Reset()
+				nl := sort(head, &toucher{})
+				checkSorted(nl, n, names[i])
+
+				t := totals[i]
+				col := 0
+				if len(caches.Levels) > 0 {
+					t[col] += caches.Levels[0].Accesses
+				} else if caches.TLB != nil {
+					t[col] += caches.TLB.Accesses
+				}
+				for _, c := range caches.Levels {
+					col++
+					t[col] += c.Misses
+				}
+				if caches.TLB != nil {
+					col++
+					t[col] += caches.TLB.Misses
+				}
+
+				head = listgen.ResetList(order)
+			}
+		}
+
+		fmt.Printf("%d", n)
+		for _, t := range totals {
+			for _, v := range t {
+				fmt.Printf("\t%d", v / *iterations)
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+}
+
+func checkSorted(head *Node, nominalSize int, phrase string) {
+	if sz, sorted := listsort.IsSorted(head); !sorted {
+		log.Printf("list of size %d not sorted at element %d, %s\n", nominalSize, sz, phrase)
+		os.Exit(1)
+	} else if sz != nominalSize {
+		log.Printf("list of size %d had %d elements after %s sort\n", nominalSize, sz, phrase)
+		os.Exit(2)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"math/rand"
+	"math/rand/v2"
 	"os"
 	"time"
 	"unsafe"
+
+	"mergesort/prng"
 )
 
 // Node is an element of a linked list
@@ -29,17 +31,26 @@ func main() {
 	countBegin := flag.Int("b", 1000, "beginning list size")
 	countUntil := flag.Int("u", 18000000, "sort lists up to this size")
 
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano() | int64(os.Getpid()))
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	var err error
+	if rng, err = prng.New(*prngName, *seed); err != nil {
+		log.Fatal(err)
+	}
 	hostname, _ := os.Hostname() // not going to fail
 
 	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
 	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
 		*countBegin, *countUntil, *countIncrement)
 
 	fmt.Print("# idiomatic list in-memory ordering\n")
-	fmt.Print("# math/rand random numbers as list node values\n")
+	fmt.Printf("# %s random numbers as list node values\n", *prngName)
 	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
 
 	var listCreation func(int) *Node
@@ -132,13 +143,16 @@ func isSorted(head *Node) (int, bool) {
 	return sz, true
 }
 
+// rng chooses the data values of random lists.
+var rng *rand.Rand
+
 func randomValueList(n int) *Node {
 
 	var head *Node
 
 	for i := 0; i < n; i++ {
 		head = &Node{
-			Data:  uint(rand.Int()),
+			Data:  uint(rng.Int()),
 			Next:  head,
 			Reset: head,
 		}
@@ -5,97 +5,173 @@ package main
  */
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
 	"log"
-	"math/rand"
 	"os"
 	"time"
 	"unsafe"
+
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/listsort/generic"
+	"mergesort/prng"
 )
 
 // Node is an element of a linked list
-type Node struct {
-	Data  uint
-	Reset *Node // another chain through the linked list
-	Next  *Node
-}
+type Node = listsort.Node
 
 func main() {
 	alreadySorted := flag.Bool("s", false, "already sorted low-to-high list")
 	reverseSorted := flag.Bool("S", false, "reverse sorted high-to-low list")
+	distribution := flag.String("v", "", "data value distribution: "+listgen.Distributions)
+	keyFile := flag.String("f", "", "read data values from this file, - for stdin, instead of generating them")
+	dumpFile := flag.String("w", "", "write the first list of each size to this file, size appended to the name")
+	binaryKeys := flag.Bool("x", false, "-f and -w files hold 8-byte little-endian binary data values, not text")
 
 	countIncrement := flag.Int("i", 200000, "increment of list size")
 	countBegin := flag.Int("b", 1000, "beginning list size")
 	countUntil := flag.Int("u", 18000000, "sort lists up to this size")
 
 	iterations := flag.Int("I", 10, "number of sorts conducted at any given list length")
+	reverseRuns := flag.Bool("D", false, "natural mergesort also reverses descending runs")
+	minGallop := flag.Int("W", 7, "galloping merges gallop after this many wins in a row")
 
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano() | int64(os.Getpid()))
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
+
+	var keys []uint
+	if *keyFile != "" {
+		if *alreadySorted || *reverseSorted || *distribution != "" {
+			log.Fatalf("-f doesn't allow -s, -S or -v\n")
+		}
+		if keys, err = listgen.ReadKeyFile(*keyFile, *binaryKeys); err != nil {
+			log.Fatal(err)
+		}
+		// lists can't be longer than the file
+		*countUntil = min(*countUntil, len(keys)+1)
+	}
+
 	hostname, _ := os.Hostname() // not going to fail
 
 	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
 	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
 		*countBegin, *countUntil, *countIncrement)
 	fmt.Printf("# %d iterations of a given list length\n", *iterations)
 
 	fmt.Print("# idiomatic list in-memory ordering\n")
-	fmt.Print("# math/rand random numbers as list node values\n")
+	fmt.Printf("# %s random numbers as list node values\n", *prngName)
 	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
 
-	var listCreation func(int) *Node
-	listCreation = randomValueList
+	var listCreation func(int, bool) *Node
+	listCreation = listgen.RandomValueList
 	listCreationPhrase := "randomly chosen data"
 	if *alreadySorted {
-		listCreation = presortedList
+		listCreation = listgen.PresortedList
 		listCreationPhrase = "presorted"
 	}
 	if *reverseSorted {
-		listCreation = reverseSortedList
+		listCreation = listgen.ReverseSortedList
 		listCreationPhrase = "reverse sorted"
 	}
+	if *distribution != "" {
+		if *alreadySorted || *reverseSorted {
+			log.Fatalf("-v doesn't allow -s or -S\n")
+		}
+		var err error
+		if listCreation, listCreationPhrase, err = listgen.Distribution(*distribution); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if keys != nil {
+		listCreation = listgen.KeyList(keys)
+		listCreationPhrase = "file"
+		fmt.Printf("# %d data values read from %s\n", len(keys), *keyFile)
+	}
 	fmt.Printf("# %s data values\n", listCreationPhrase)
+	if *dumpFile != "" {
+		// write out the first list of each size, before it's sorted
+		createList, dumped := listCreation, -1
+		listCreation = func(n int, useCheapRand bool) *Node {
+			head := createList(n, useCheapRand)
+			if n != dumped {
+				dumped = n
+				if err := listgen.WriteKeyFile(fmt.Sprintf("%s.%d", *dumpFile, n), head, *binaryKeys); err != nil {
+					log.Fatal(err)
+				}
+			}
+			return head
+		}
+		fmt.Printf("# first list of each size written to %s.<size>\n", *dumpFile)
+	}
+
+	naturalMergesort := generic.NaturalMergesort[uint]
+	if *reverseRuns {
+		naturalMergesort = generic.NaturalMergesortReversing[uint]
+		fmt.Println("# natural mergesort reverses descending runs")
+	}
+	fmt.Printf("# galloping merges gallop after %d wins in a row\n", *minGallop)
+	fmt.Println("# size, recursive, bottom up, iterative, natural, recursive galloping, bottom up galloping, list_sort")
+
+	// Every sort is the generic version, called with compare,
+	// so the comparisons counted are the ones listsort's sorts do.
+	// The natural mergesort count includes the comparisons made finding
+	// runs, the galloping counts include those made while galloping.
+	sorts := []struct {
+		name string
+		sort func(*Node) *Node
+	}{
+		{"recursive", func(head *Node) *Node { return generic.RecursiveMergeSort(head, compare) }},
+		{"bottom up", func(head *Node) *Node { return generic.BUMergesort(head, compare) }},
+		{"iterative", func(head *Node) *Node { return generic.Mergesort(head, compare) }},
+		{"natural", func(head *Node) *Node { return naturalMergesort(head, compare) }},
+		{"recursive galloping", func(head *Node) *Node { return generic.RecursiveMergeSortGalloping(head, *minGallop, compare) }},
+		{"bottom up galloping", func(head *Node) *Node { return generic.BUMergesortGalloping(head, *minGallop, compare) }},
+		{"list_sort", func(head *Node) *Node { return generic.ListSort(head, compare) }},
+	}
 
 	for n := *countBegin; n < *countUntil; n += *countIncrement {
 
-		// Zero out global comparison counters
-		recursiveComparisonCount = 0
-		buComparisonCount = 0
-		iterativeComparisonCount = 0
+		counts := make([]int, len(sorts))
 
 		for j := 0; j < *iterations; j++ {
-			head := listCreation(n)
-
-			// recursive mergesort, check list
-			nl := recursiveMergeSort(head)
-			checkSorted(nl, n, "recursive")
-			resetList(head, n, "recursive")
-
-			// bottom up mergesort, check list
-			nl = buMergesort(head)
-			checkSorted(nl, n, "bottom up")
-			resetList(head, n, "bottom up")
-
-			// iterative mergesort, check list
-			nl = mergesort(head)
-			checkSorted(nl, n, "iterative")
+			head := listCreation(n, true)
+			// original order of nodes restorable
+			order := listgen.NodeOrder(head)
+
+			for k, s := range sorts {
+				comparisons = 0
+				nl := s.sort(head)
+				counts[k] += comparisons
+				checkSorted(nl, n, s.name)
+				head = listgen.ResetList(order)
+			}
 		}
 
-		fmt.Printf("%d\t%d\t%d\t%d\n",
-			n,
-			recursiveComparisonCount / *iterations,
-			buComparisonCount / *iterations,
-			iterativeComparisonCount / *iterations,
-		)
+		fmt.Printf("%d", n)
+		for _, count := range counts {
+			fmt.Printf("\t%d", count / *iterations)
+		}
+		fmt.Println()
 	}
 
 	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
 }
 
 func checkSorted(head *Node, nominalSize int, phrase string) {
-	if sz, sorted := isSorted(head); !sorted {
+	if sz, sorted := listsort.IsSorted(head); !sorted {
 		log.Printf("list of size %d not sorted at element %d, %s\n", nominalSize, sz, phrase)
 		os.Exit(1)
 	} else if sz != nominalSize {
@@ -104,320 +180,12 @@ func checkSorted(head *Node, nominalSize int, phrase string) {
 	}
 }
 
-// resetList walks the list that starts with head
-// via .Reset pointers. It sets .Next to .Reset
-// in every list node, restoring the original node
-// order.
-func resetList(head *Node, nominalLength int, phrase string) {
-	length := 0
-	for node := head; node != nil; node = node.Reset {
-		node.Next = node.Reset
-		length++
-	}
-
-	if length != nominalLength {
-		log.Printf("reset after %s sort, reset list %d nodes, should have been %d\n",
-			phrase, length, nominalLength)
-		os.Exit(3)
-	}
-}
-
-func isSorted(head *Node) (int, bool) {
-	if head == nil {
-		return 0, true
-	}
-	if head.Next == nil {
-		return 1, true
-	}
-	var sz int
-	for ; head.Next != nil; head = head.Next {
-		sz++
-		if head.Data > head.Next.Data {
-			return sz, false
-		}
-	}
-	sz++ // for-loop checks head.Next, count final element on list
-	return sz, true
-}
-
-func randomValueList(n int) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		head = &Node{
-			Data:  uint(rand.Int()),
-			Next:  head,
-			Reset: head,
-		}
-	}
-
-	return head
-}
-
-func presortedList(n int) *Node {
-
-	var head *Node
-
-	for i := n - 1; i >= 0; i-- {
-		head = &Node{
-			Data:  uint(i),
-			Next:  head,
-			Reset: head,
-		}
-	}
-
-	return head
-}
-
-func reverseSortedList(n int) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		head = &Node{
-			Data:  uint(i),
-			Next:  head,
-			Reset: head,
-		}
-	}
-
-	return head
-}
-
-// Print runs a linked list and prints its values on stdout
-func Print(list *Node) {
-	for node := list; node != nil; node = node.Next {
-		fmt.Printf("%d -> ", node.Data)
-	}
-	fmt.Println()
-}
-
-func listSize(node *Node) int {
-	count := 0
-	for ; node != nil; node = node.Next {
-		count++
-	}
-	return count
-}
-
-var recursiveComparisonCount int
-
-func recursiveMergeSort(head *Node) *Node {
-	if head.Next == nil {
-		// single node list is sorted by definition
-		return head
-	}
-
-	// because of recursion bottoming out at a 1-long-list,
-	// head points to a list of at least 2 elements.
-
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
-		}
-	}
-
-	right := *turtle
-	*turtle = nil
-
-	left := recursiveMergeSort(head)
-	right = recursiveMergeSort(right)
-
-	// Set h, t variables so that the loop doing the merge
-	// does not have to have a "if h == nil" check every iteration.
-	x := &right
-	recursiveComparisonCount++
-	if left.Data < right.Data {
-		x = &left
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
-	// than the other. Have to check both for nil.
-	for left != nil && right != nil {
-		n := &right
-		recursiveComparisonCount++
-		if left.Data < right.Data {
-			n = &left
-		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
-		// At the end of this for-loop, t.Next ends up being nil
-		// because of the left/right list splitting.
-	}
-
-	// Either left or right are nil. If left == nil,
-	// assigning nil to t.Next is no issue.
-	t.Next = left
-	if right != nil {
-		// but if right is nil, can't assign nil to t.Next,
-		// because left was non-nil.
-		t.Next = right
-	}
-
-	return h
-}
-
-var buComparisonCount int
-
-// buMergesort - transliteration of Wikipedia's "Bottom up implementation with lists",
-// https://en.wikipedia.org/wiki/Merge_sort#Bottom-up_implementation_using_lists
-func buMergesort(head *Node) *Node {
-	if head == nil {
-		return nil
-	}
-	// Can pass 1-length lists to the rest of the function,
-	// because array[0] is 2^0 or 1 in size
-
-	var array [32]*Node
-	var result, next *Node
-	var i int
-
-	result = head
-
-	for result != nil {
-		next = result.Next
-		result.Next = nil
-
-		for i = 0; (i < 32) && (array[i] != nil); i++ {
-			result = merge(array[i], result)
-			array[i] = nil
-		}
-		if i == 32 {
-			i--
-		}
-		array[i] = result
-		result = next
-	}
-
-	result = nil
-	for i = 0; i < 32; i++ {
-		result = merge(array[i], result)
-	}
-
-	return result
-}
-
-// merge for bottom up - it counts comparisons for that algorithm
-func merge(p *Node, q *Node) *Node {
-	if p == nil {
-		return q
-	}
-	if q == nil {
-		return p
-	}
-
-	x := &q
-	buComparisonCount++
-	if p.Data < q.Data {
-		x = &p
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	for p != nil && q != nil {
-		n := &q
-		buComparisonCount++
-		if p.Data < q.Data {
-			n = &p
-		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
-	}
-
-	t.Next = p
-	if q != nil {
-		t.Next = q
-	}
-
-	return h
-}
-
-var iterativeComparisonCount int
-
-func mergesort(head *Node) *Node {
-
-	var hd, tl *Node
-	appnd := func(n *Node) {
-		if hd == nil {
-			hd = n
-			tl = n
-			return
-		}
-		tl.Next = n
-		tl = n
-	}
-
-	p := head
-	mergecount := 2 // just to pass the first for-test
-
-	// The final pass over the unsorted linked list merges
-	// two lists each of about half the number of nodes.
-	// mergecount will have value 1 in that case. Don't
-	// need to loop again.
-	for k := 1; mergecount > 1; k *= 2 {
-
-		mergecount = 0
-
-		for p != nil {
-
-			psize := 0
-			q := p
-			for i := 0; q != nil && i < k; i++ {
-				psize++
-				q = q.Next
-			}
-
-			qsize := psize
-
-			for psize > 0 && qsize > 0 && q != nil {
-				iterativeComparisonCount++
-				if p.Data < q.Data {
-					appnd(p)
-					p = p.Next
-					psize--
-					continue
-				}
-				appnd(q)
-				q = q.Next
-				qsize--
-			}
-
-			for ; psize > 0 && p != nil; psize-- {
-				appnd(p)
-				p = p.Next
-			}
-
-			for ; qsize > 0 && q != nil; qsize-- {
-				appnd(q)
-				q = q.Next
-			}
-
-			p = q
-
-			mergecount++
-		}
-
-		p = hd
-		head = hd
-
-		hd = nil
-		tl.Next = nil
-		tl = nil
-	}
+// comparisons counts data value comparisons, every sort
+// adds to it by calling compare.
+var comparisons int
 
-	return head
+// compare is cmp.Compare, counting every comparison
+func compare(a, b uint) int {
+	comparisons++
+	return cmp.Compare(a, b)
 }
@@ -0,0 +1,225 @@
+// Package extsort sorts more data values than fit in memory.
+// A Sorter collects data values into linked lists of at most
+// a memory budget's worth of nodes, sorts each list with one of
+// the listsort mergesorts, and writes the sorted list to a
+// temporary run file. When all the data values have been added,
+// the Sorter k-way merges the run files into an output file,
+// or into a single linked list.
+package extsort
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unsafe"
+
+	"mergesort/listsort"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+// Sorter does an external mergesort. BytesWritten and BytesRead
+// count the bytes of run files and output written,
+// and the bytes of run files read back.
+type Sorter struct {
+	BytesWritten int64
+	BytesRead    int64
+
+	dir      string
+	budget   int // nodes
+	sortList func(*Node) *Node
+
+	head  *Node // nodes not yet written to a run file
+	count int   // nodes on the head list
+	free  *Node // nodes of runs already written, for re-use
+	runs  []string
+}
+
+// NewSorter returns a Sorter that creates run files in directory dir,
+// and keeps at most budget bytes of list nodes in memory at one time.
+// The sortList function sorts each run in memory before writing it.
+func NewSorter(dir string, budget int64, sortList func(*Node) *Node) *Sorter {
+	nodes := int(budget / int64(unsafe.Sizeof(Node{})))
+	if nodes < 1 {
+		nodes = 1
+	}
+	return &Sorter{
+		dir:      dir,
+		budget:   nodes,
+		sortList: sortList,
+	}
+}
+
+// Add puts another data value into the sort. When a memory budget's
+// worth of values have been added, Add sorts them and writes them
+// to a new run file.
+func (s *Sorter) Add(value uint) error {
+	node := s.free
+	if node != nil {
+		s.free = node.Next
+	} else {
+		node = &Node{}
+	}
+	node.Data = value
+	node.Next = s.head
+	s.head = node
+	s.count++
+
+	if s.count >= s.budget {
+		return s.writeRun()
+	}
+	return nil
+}
+
+// Runs is the number of run files written so far
+func (s *Sorter) Runs() int {
+	return len(s.runs)
+}
+
+func (s *Sorter) writeRun() error {
+	if s.head == nil {
+		return nil
+	}
+
+	fout, err := os.CreateTemp(s.dir, "run*.bin")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, fout.Name())
+
+	sorted := s.sortList(s.head)
+	w := NewWriter(countingWriter{w: fout, count: &s.BytesWritten})
+	var tail *Node
+	for node := sorted; node != nil; node = node.Next {
+		if err := w.WriteValue(node.Data); err != nil {
+			fout.Close()
+			return err
+		}
+		tail = node
+	}
+	if err := w.Flush(); err != nil {
+		fout.Close()
+		return err
+	}
+
+	// The run's nodes get re-used for the next run
+	tail.Next = s.free
+	s.free = sorted
+	s.head = nil
+	s.count = 0
+
+	return fout.Close()
+}
+
+// run is a min-heap element for merging run files
+type run struct {
+	value  uint
+	reader *Reader
+}
+
+// Merge writes every data value added to the Sorter to w
+// in ascending order, in the run file format,
+// and returns the number of values written.
+func (s *Sorter) Merge(w io.Writer) (int, error) {
+	out := NewWriter(countingWriter{w: w, count: &s.BytesWritten})
+	count, err := s.merge(out.WriteValue)
+	if err != nil {
+		return count, err
+	}
+	return count, out.Flush()
+}
+
+// MergeList returns a sorted linked list of every data value
+// added to the Sorter. The list has to fit in memory.
+func (s *Sorter) MergeList() (*Node, error) {
+	var head *Node
+	tail := &head
+	_, err := s.merge(func(value uint) error {
+		*tail = &Node{Data: value}
+		tail = &(*tail).Next
+		return nil
+	})
+	return head, err
+}
+
+// merge k-way merges the run files, passing data values
+// in ascending order to function emit.
+func (s *Sorter) merge(emit func(uint) error) (int, error) {
+	if err := s.writeRun(); err != nil {
+		return 0, err
+	}
+	s.free = nil
+
+	var heap []run
+	for _, name := range s.runs {
+		fin, err := os.Open(name)
+		if err != nil {
+			return 0, err
+		}
+		defer fin.Close()
+		r := NewReader(countingReader{r: fin, count: &s.BytesRead})
+		value, err := r.ReadValue()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("run file %s: %w", name, err)
+		}
+		heap = append(heap, run{value: value, reader: r})
+	}
+	for i := len(heap)/2 - 1; i >= 0; i-- {
+		siftDown(heap, i)
+	}
+
+	count := 0
+	for len(heap) > 0 {
+		if err := emit(heap[0].value); err != nil {
+			return count, err
+		}
+		count++
+		value, err := heap[0].reader.ReadValue()
+		switch {
+		case err == io.EOF:
+			heap[0] = heap[len(heap)-1]
+			heap = heap[:len(heap)-1]
+		case err != nil:
+			return count, err
+		default:
+			heap[0].value = value
+		}
+		siftDown(heap, 0)
+	}
+
+	return count, nil
+}
+
+func siftDown(heap []run, i int) {
+	for {
+		least := i
+		left := 2*i + 1
+		if left < len(heap) && heap[left].value < heap[least].value {
+			least = left
+		}
+		if right := left + 1; right < len(heap) && heap[right].value < heap[least].value {
+			least = right
+		}
+		if least == i {
+			return
+		}
+		heap[i], heap[least] = heap[least], heap[i]
+		i = least
+	}
+}
+
+// Close removes the Sorter's run files
+func (s *Sorter) Close() error {
+	var firstErr error
+	for _, name := range s.runs {
+		if err := os.Remove(name); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	s.runs = nil
+	return firstErr
+}
@@ -0,0 +1,91 @@
+package extsort
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrOutOfOrder means a data value written to a Writer
+// was less than the previously written data value.
+var ErrOutOfOrder = errors.New("extsort: data values written out of order")
+
+// Writer writes ascending data values in the run file format:
+// each value is written as the unsigned varint difference from
+// the previous value, the first value as the difference from 0.
+// Sorted runs have small differences, so most values take
+// only a byte or two.
+type Writer struct {
+	w    *bufio.Writer
+	prev uint
+	buf  [binary.MaxVarintLen64]byte
+}
+
+// NewWriter returns a Writer that buffers its writes to w.
+// Call Flush after the last value.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// WriteValue writes one data value, which can't be less
+// than the previous data value written.
+func (w *Writer) WriteValue(value uint) error {
+	if value < w.prev {
+		return ErrOutOfOrder
+	}
+	n := binary.PutUvarint(w.buf[:], uint64(value-w.prev))
+	w.prev = value
+	_, err := w.w.Write(w.buf[:n])
+	return err
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Reader reads data values that a Writer wrote
+type Reader struct {
+	r    *bufio.Reader
+	prev uint
+}
+
+// NewReader returns a Reader that buffers its reads from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// ReadValue returns the next data value, or io.EOF
+// after the last one.
+func (r *Reader) ReadValue() (uint, error) {
+	delta, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, err
+	}
+	r.prev += uint(delta)
+	return r.prev, nil
+}
+
+// countingWriter and countingReader keep track of bytes of I/O
+type countingWriter struct {
+	w     io.Writer
+	count *int64
+}
+
+func (cw countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	*cw.count += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r     io.Reader
+	count *int64
+}
+
+func (cr countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	*cr.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,276 @@
+package main
+
+/*
+ * Time and count comparisons of k-way merging and k-way mergesort
+ */
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+	"unsafe"
+
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/listsort/generic"
+	"mergesort/prng"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+func main() {
+	kwaySort := flag.Bool("M", false, "k-way mergesort a list instead of merging k sorted lists")
+	alreadySorted := flag.Bool("s", false, "already sorted low-to-high list")
+	reverseSorted := flag.Bool("S", false, "reverse sorted high-to-low list")
+
+	countIncrement := flag.Int("i", 1000000, "increment of total list size")
+	countBegin := flag.Int("b", 1000, "beginning total list size")
+	countUntil := flag.Int("u", 5000000, "merge or sort lists up to this total size")
+
+	kBegin := flag.Int("k", 2, "beginning number of lists to merge")
+	kUntil := flag.Int("K", 64, "merge up to this number of lists")
+	kMultiplier := flag.Int("x", 2, "multiply number of lists by this between sets of merges")
+
+	iterations := flag.Int("I", 10, "number of merges or sorts conducted at any given list length and k")
+
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
+	flag.Parse()
+
+	if *kBegin < 2 || *kMultiplier < 2 {
+		log.Fatal("need at least 2 lists to merge, and a k multiplier of at least 2")
+	}
+
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
+	hostname, _ := os.Hostname() // not going to fail
+
+	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
+	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
+		*countBegin, *countUntil, *countIncrement)
+	fmt.Printf("# Start at k = %d lists, end at %d lists, multiply by %d\n",
+		*kBegin, *kUntil, *kMultiplier)
+	fmt.Printf("# %d iterations of a given list length and k\n", *iterations)
+	if *kwaySort {
+		fmt.Println("# k-way mergesort, compared to bottom up mergesort")
+	} else {
+		fmt.Println("# k-way merge of k sorted lists, compared to a tournament of pairwise merges")
+	}
+
+	fmt.Print("# idiomatic list in-memory ordering\n")
+	fmt.Printf("# %s random numbers as list node values\n", *prngName)
+	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
+
+	var listCreation func(int, bool) *Node
+	listCreation = listgen.RandomValueList
+	listCreationPhrase := "randomly chosen data"
+	if *alreadySorted {
+		listCreation = listgen.PresortedList
+		listCreationPhrase = "presorted"
+	}
+	if *reverseSorted {
+		listCreation = listgen.ReverseSortedList
+		listCreationPhrase = "reverse sorted"
+	}
+	fmt.Printf("# %s data values\n", listCreationPhrase)
+	fmt.Println("# total length, k, k-way mean comparisons, other mean comparisons, " +
+		"k-way mean ET, looping time, k-way min ET, k-way max ET, other mean ET, other min ET, other max ET")
+
+	for n := *countBegin; n < *countUntil; n += *countIncrement {
+		for k := *kBegin; k <= *kUntil; k *= *kMultiplier {
+
+			var kwayComparisons, otherComparisons int
+			var kwayTotal, otherTotal time.Duration
+			var looping time.Duration
+			kwayMin, otherMin := time.Duration(math.MaxInt64), time.Duration(math.MaxInt64)
+			var kwayMax, otherMax time.Duration
+
+			for j := 0; j < *iterations; j++ {
+				beforeIteration := time.Now()
+
+				var lists [][]*Node // each list's original node order
+				if *kwaySort {
+					lists = [][]*Node{listgen.NodeOrder(listCreation(n, true))}
+				} else {
+					lists = sortedLists(listCreation(n, true), n, k)
+				}
+
+				// time uint k-way, then count generic k-way comparisons,
+				// then the same for the other way of merging or sorting.
+				before := time.Now()
+				nl := kwayFunction(*kwaySort, k)(resetLists(lists))
+				elapsed := time.Since(before)
+				kwayTotal += elapsed
+				kwayMin, kwayMax = min(kwayMin, elapsed), max(kwayMax, elapsed)
+				checkSorted(nl, n, "k-way")
+
+				comparisonCount = 0
+				nl = countedKwayFunction(*kwaySort, k)(resetLists(lists))
+				kwayComparisons += comparisonCount
+				checkSorted(nl, n, "counted k-way")
+
+				before = time.Now()
+				nl = otherFunction(*kwaySort)(resetLists(lists))
+				elapsed = time.Since(before)
+				otherTotal += elapsed
+				otherMin, otherMax = min(otherMin, elapsed), max(otherMax, elapsed)
+				checkSorted(nl, n, "other")
+
+				comparisonCount = 0
+				nl = countedOtherFunction(*kwaySort)(resetLists(lists))
+				otherComparisons += comparisonCount
+				checkSorted(nl, n, "counted other")
+
+				looping += time.Since(beforeIteration)
+			}
+
+			kwayTotal /= time.Duration(*iterations)
+			otherTotal /= time.Duration(*iterations)
+			fmt.Printf("%d\t%d\t%d\t%d\t%.04f\t%.04f\t%.04f\t%.04f\t%.04f\t%.04f\t%.04f\n",
+				n, k,
+				kwayComparisons / *iterations,
+				otherComparisons / *iterations,
+				kwayTotal.Seconds(),
+				looping.Seconds(),
+				kwayMin.Seconds(),
+				kwayMax.Seconds(),
+				otherTotal.Seconds(),
+				otherMin.Seconds(),
+				otherMax.Seconds(),
+			)
+		}
+	}
+
+	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+}
+
+// sortedLists cuts an n-node list into k lists of nearly equal length,
+// sorts each of them, and returns each sorted list's node order.
+func sortedLists(head *Node, n int, k int) [][]*Node {
+	var lists [][]*Node
+	for i := 0; i < k; i++ {
+		sublistSize := n / k
+		if i < n%k {
+			sublistSize++
+		}
+		if sublistSize == 0 {
+			break
+		}
+		tail := head
+		for j := 1; j < sublistSize; j++ {
+			tail = tail.Next
+		}
+		next := tail.Next
+		tail.Next = nil
+		lists = append(lists, listgen.NodeOrder(listsort.BUMergesort(head)))
+		head = next
+	}
+	return lists
+}
+
+// resetLists puts every list back in its original node order
+func resetLists(lists [][]*Node) []*Node {
+	heads := make([]*Node, len(lists))
+	for i := range lists {
+		heads[i] = listgen.ResetList(lists[i])
+	}
+	return heads
+}
+
+func kwayFunction(kwaySort bool, k int) func([]*Node) *Node {
+	if kwaySort {
+		return func(heads []*Node) *Node {
+			return listsort.KWayMergeSort(heads[0], k)
+		}
+	}
+	return listsort.KWayMerge
+}
+
+func countedKwayFunction(kwaySort bool, k int) func([]*Node) *Node {
+	if kwaySort {
+		return func(heads []*Node) *Node {
+			return generic.KWayMergeSort(heads[0], k, countingCompare)
+		}
+	}
+	return func(heads []*Node) *Node {
+		return generic.KWayMerge(heads, countingCompare)
+	}
+}
+
+// otherFunction returns the bottom up mergesort to compare with k-way mergesort,
+// or a tournament of pairwise merges to compare with a k-way merge.
+func otherFunction(kwaySort bool) func([]*Node) *Node {
+	if kwaySort {
+		return func(heads []*Node) *Node {
+			return listsort.BUMergesort(heads[0])
+		}
+	}
+	return func(heads []*Node) *Node {
+		return pairwiseMerge(heads, listsort.Merge)
+	}
+}
+
+func countedOtherFunction(kwaySort bool) func([]*Node) *Node {
+	if kwaySort {
+		return func(heads []*Node) *Node {
+			return generic.BUMergesort(heads[0], countingCompare)
+		}
+	}
+	return func(heads []*Node) *Node {
+		return pairwiseMerge(heads, func(p, q *Node) *Node {
+			return generic.Merge(p, q, countingCompare)
+		})
+	}
+}
+
+// pairwiseMerge merges lists in a balanced tournament: each round
+// merges the first and second lists, the third and fourth and so on,
+// until one list is left. Every node takes part in about log2(k)
+// merges, the same O(log k) comparisons per node as a k-way merge.
+// The earlier list of each pair goes first, keeping the merge stable.
+func pairwiseMerge(heads []*Node, merge func(p, q *Node) *Node) *Node {
+	if len(heads) == 0 {
+		return nil
+	}
+	for len(heads) > 1 {
+		merged := heads[:0]
+		for i := 0; i < len(heads); i += 2 {
+			if i+1 == len(heads) {
+				merged = append(merged, heads[i])
+				break
+			}
+			merged = append(merged, merge(heads[i], heads[i+1]))
+		}
+		heads = merged
+	}
+	return heads[0]
+}
+
+var comparisonCount int
+
+func countingCompare(a, b uint) int {
+	comparisonCount++
+	return cmp.Compare(a, b)
+}
+
+func checkSorted(head *Node, nominalSize int, phrase string) {
+	if sz, sorted := listsort.IsSorted(head); !sorted {
+		log.Printf("list of size %d not sorted at element %d, %s\n", nominalSize, sz, phrase)
+		os.Exit(1)
+	} else if sz != nominalSize {
+		log.Printf("list of size %d had %d elements after %s merge\n", nominalSize, sz, phrase)
+		os.Exit(2)
+	}
+}
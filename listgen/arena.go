@@ -0,0 +1,63 @@
+package listgen
+
+// Arena allocates list nodes by carving them out of big slabs,
+// the way mergetest.c's perform_preallocation carves nodes out of
+// one calloc'd block. Nodes from a slab are adjacent in memory,
+// in the order they're allocated, and allocating one costs
+// an index increment instead of a trip through the Go allocator.
+type Arena struct {
+	slabSize int
+	slabs    []slab
+	slab     int // index of the slab Alloc carves nodes from
+	next     int // index in that slab of the next node to carve
+}
+
+// NewArena creates an Arena that allocates slabs of slabSize nodes,
+// as Alloc needs them. The nodes are the size SetNodeSize last set,
+// and a slab's nodes are exactly that many bytes apart.
+func NewArena(slabSize int) *Arena {
+	if slabSize < 1 {
+		slabSize = 1
+	}
+	return &Arena{slabSize: slabSize}
+}
+
+// Alloc returns the next unused node of the arena's slabs,
+// allocating a new slab if all of them are used up.
+func (a *Arena) Alloc() *Node {
+	if a.slab < len(a.slabs) && a.next == a.slabs[a.slab].len {
+		a.slab++
+		a.next = 0
+	}
+	if a.slab == len(a.slabs) {
+		a.slabs = append(a.slabs, makeSlab(a.slabSize))
+	}
+	node := a.slabs[a.slab].at(a.next)
+	a.next++
+	return node
+}
+
+// Reset zeroes every node the arena has handed out,
+// and has Alloc start over at the first node of the first slab,
+// like mergetest.c's free_list does with preallocated nodes.
+// Lists built from the arena's nodes are garbage after Reset.
+func (a *Arena) Reset() {
+	for i := range a.slabs {
+		a.slabs[i].clear(a.slabs[i].len)
+	}
+	a.slab = 0
+	a.next = 0
+}
+
+// UseArena makes the list generators allocate nodes from arena,
+// instead of allocating each node separately. A Pool set with
+// UsePool takes the nodes it has to allocate from arena.
+// UseArena(nil) goes back to allocating each node separately,
+// at the size SetNodeSize last set, and leaves any Pool in use.
+func UseArena(arena *Arena) {
+	if arena == nil {
+		arenaNode = nil
+		return
+	}
+	arenaNode = arena.Alloc
+}
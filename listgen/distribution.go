@@ -0,0 +1,179 @@
+package listgen
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Distributions describes the data value distributions
+// that Distribution knows, for command line help.
+const Distributions = "zipf[:s], few[:k], organpipe, sawtooth[:p], nearlysorted[:k], runs[:r], range[:m], worst[:sort], best[:sort]"
+
+// Distribution returns a list generator for a named distribution
+// of data values, and a phrase describing the distribution.
+// The name can have a parameter after a colon, "runs:500" for example.
+//
+//   - zipf[:s] Zipf distributed values, exponent s > 1, default 1.1
+//   - few[:k] random values from only k unique keys, default 10
+//   - organpipe ascending to the middle of the list, then descending
+//   - sawtooth[:p] ascending runs 0 to p-1, over and over, default 100
+//   - nearlysorted[:k] presorted values, with k random pairs swapped, default 10
+//   - runs[:r] concatenated sorted runs of r random values, default 1000
+//   - range[:m] random values from 0 to m-1, default 1000
+//   - worst[:sort] most comparisons for a mergesort, see WorstCase, default recursive
+//   - best[:sort] fewest comparisons for a mergesort, see BestCase, default recursive
+func Distribution(spec string) (func(int, bool) *Node, string, error) {
+	name, param, hasParam := strings.Cut(spec, ":")
+
+	intParam := func(dflt int) (int, error) {
+		if !hasParam {
+			return dflt, nil
+		}
+		k, err := strconv.Atoi(param)
+		if err != nil || k < 1 {
+			return 0, fmt.Errorf("distribution %s: parameter %q has to be a positive integer", name, param)
+		}
+		return k, nil
+	}
+
+	var values func(n int) []uint
+	var phrase string
+
+	switch name {
+	case "zipf":
+		s := 1.1
+		if hasParam {
+			var err error
+			if s, err = strconv.ParseFloat(param, 64); err != nil || s <= 1 {
+				return nil, "", fmt.Errorf("distribution zipf: exponent %q has to be greater than 1", param)
+			}
+		}
+		values = func(n int) []uint {
+			z := rand.NewZipf(rng, s, 1, uint64(max(n-1, 1)))
+			v := make([]uint, n)
+			for i := range v {
+				v[i] = uint(z.Uint64())
+			}
+			return v
+		}
+		phrase = fmt.Sprintf("Zipf distributed, exponent %g,", s)
+	case "few":
+		k, err := intParam(10)
+		if err != nil {
+			return nil, "", err
+		}
+		values = func(n int) []uint {
+			v := make([]uint, n)
+			for i := range v {
+				v[i] = uint(rng.IntN(k))
+			}
+			return v
+		}
+		phrase = fmt.Sprintf("few unique, %d keys,", k)
+	case "organpipe":
+		values = func(n int) []uint {
+			v := make([]uint, n)
+			for i := range v {
+				v[i] = uint(min(i, n-1-i))
+			}
+			return v
+		}
+		phrase = "organ pipe"
+	case "sawtooth":
+		p, err := intParam(100)
+		if err != nil {
+			return nil, "", err
+		}
+		values = func(n int) []uint {
+			v := make([]uint, n)
+			for i := range v {
+				v[i] = uint(i % p)
+			}
+			return v
+		}
+		phrase = fmt.Sprintf("sawtooth, period %d,", p)
+	case "nearlysorted":
+		k, err := intParam(10)
+		if err != nil {
+			return nil, "", err
+		}
+		values = func(n int) []uint {
+			v := make([]uint, n)
+			for i := range v {
+				v[i] = uint(i)
+			}
+			for j := 0; j < k && n > 1; j++ {
+				a, b := rng.IntN(n), rng.IntN(n)
+				v[a], v[b] = v[b], v[a]
+			}
+			return v
+		}
+		phrase = fmt.Sprintf("nearly sorted, %d random swaps,", k)
+	case "runs":
+		r, err := intParam(1000)
+		if err != nil {
+			return nil, "", err
+		}
+		values = func(n int) []uint {
+			v := make([]uint, n)
+			for i := range v {
+				v[i] = uint(rng.Int())
+			}
+			for start := 0; start < n; start += r {
+				slices.Sort(v[start:min(start+r, n)])
+			}
+			return v
+		}
+		phrase = fmt.Sprintf("sorted runs of %d random", r)
+	case "range":
+		m, err := intParam(1000)
+		if err != nil {
+			return nil, "", err
+		}
+		values = func(n int) []uint {
+			v := make([]uint, n)
+			for i := range v {
+				v[i] = uint(rng.IntN(m))
+			}
+			return v
+		}
+		phrase = fmt.Sprintf("random, 0 to %d,", m-1)
+	case "worst", "best":
+		algorithm := "recursive"
+		if hasParam {
+			algorithm = param
+		}
+		var err error
+		if name == "worst" {
+			values, err = WorstCase(algorithm)
+		} else {
+			values, err = BestCase(algorithm)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("distribution %s: %w", name, err)
+		}
+		phrase = fmt.Sprintf("%s case for %s mergesort", name, algorithm)
+	default:
+		return nil, "", fmt.Errorf("unknown distribution %q, want one of %s", spec, Distributions)
+	}
+
+	return func(n int, _ bool) *Node {
+		return ValueList(values(n))
+	}, phrase, nil
+}
+
+// ValueList creates a list whose nodes have data values
+// in the same order as values.
+func ValueList(values []uint) *Node {
+	var head *Node
+	for i := len(values) - 1; i >= 0; i-- {
+		node := newNode()
+		node.Data = values[i]
+		node.Next = head
+		head = node
+	}
+	return head
+}
@@ -0,0 +1,116 @@
+package listgen
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNoKeys means a key file had no data values in it.
+var ErrNoKeys = errors.New("no keys")
+
+// ReadKeys reads data values from r, either as text, one decimal
+// value per line, or as binary, 8-byte little-endian values.
+// Text lines that are blank or start with '#' don't count.
+func ReadKeys(r io.Reader, binaryKeys bool) ([]uint, error) {
+	br := bufio.NewReader(r)
+	var keys []uint
+
+	if binaryKeys {
+		var buf [8]byte
+		for {
+			_, err := io.ReadFull(br, buf[:])
+			if err == io.EOF {
+				return keys, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("binary key %d: %w", len(keys), err)
+			}
+			keys = append(keys, uint(binary.LittleEndian.Uint64(buf[:])))
+		}
+	}
+
+	scanner := bufio.NewScanner(br)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, err := strconv.ParseUint(line, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		keys = append(keys, uint(key))
+	}
+	return keys, scanner.Err()
+}
+
+// ReadKeyFile reads data values from the file at path, or from stdin
+// if path is "-", the same way ReadKeys does. A file without
+// any data values is an error, ErrNoKeys.
+func ReadKeyFile(path string, binaryKeys bool) ([]uint, error) {
+	r := io.Reader(os.Stdin)
+	if path != "-" {
+		fin, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer fin.Close()
+		r = fin
+	}
+	keys, err := ReadKeys(r, binaryKeys)
+	if err == nil && len(keys) == 0 {
+		err = ErrNoKeys
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// KeyList returns a list generator that creates lists
+// of the first n of keys, in order.
+func KeyList(keys []uint) func(int, bool) *Node {
+	return func(n int, _ bool) *Node {
+		return ValueList(keys[:min(n, len(keys))])
+	}
+}
+
+// WriteKeys writes the data values of a nil-terminated list to w,
+// in list order, in a format ReadKeys reads.
+func WriteKeys(w io.Writer, head *Node, binaryKeys bool) error {
+	bw := bufio.NewWriter(w)
+	var buf [8]byte
+	for node := head; node != nil; node = node.Next {
+		var err error
+		if binaryKeys {
+			binary.LittleEndian.PutUint64(buf[:], uint64(node.Data))
+			_, err = bw.Write(buf[:])
+		} else {
+			_, err = bw.WriteString(strconv.FormatUint(uint64(node.Data), 10) + "\n")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteKeyFile creates the file at path, and writes
+// the data values of a list to it with WriteKeys.
+func WriteKeyFile(path string, head *Node, binaryKeys bool) error {
+	fout, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteKeys(fout, head, binaryKeys); err != nil {
+		fout.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return fout.Close()
+}
@@ -0,0 +1,258 @@
+// Package listgen creates linked lists for the benchmark programs
+// to sort: random, presorted and reverse sorted data values,
+// and lists whose nodes are arranged in memory in particular orders.
+package listgen
+
+import (
+	crand "crypto/rand"
+	"log"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"unsafe"
+
+	"mergesort/listsort"
+)
+
+// Node is the list element all the sorts work on
+type Node = listsort.Node
+
+var maxInt = big.NewInt(math.MaxInt32)
+
+// rng makes all of listgen's random choices.
+var rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+
+// UseRand makes listgen take all its randomly chosen data values,
+// shuffles and other random choices from r, so that runs
+// seeding r the same way create the same lists.
+func UseRand(r *rand.Rand) {
+	rng = r
+}
+
+// RandomValueList creates an n-node list with randomly chosen data values,
+// allocating nodes "idiomatically".
+func RandomValueList(n int, useCheapRand bool) *Node {
+
+	var head *Node
+
+	for i := 0; i < n; i++ {
+		node := newNode()
+		node.Data = RandomValue(useCheapRand)
+		node.Next = head
+		head = node
+	}
+
+	return head
+}
+
+// PresortedList creates an n-node list with data values 0 to n-1, low-to-high.
+func PresortedList(n int, _ bool) *Node {
+
+	var head *Node
+
+	for i := n - 1; i >= 0; i-- {
+		node := newNode()
+		node.Data = uint(i)
+		node.Next = head
+		head = node
+	}
+
+	return head
+}
+
+// ReverseSortedList creates an n-node list with data values n-1 to 0, high-to-low.
+func ReverseSortedList(n int, _ bool) *Node {
+
+	var head *Node
+
+	for i := 0; i < n; i++ {
+		node := newNode()
+		node.Data = uint(i)
+		node.Next = head
+		head = node
+	}
+
+	return head
+}
+
+// MemoryOrderedList creates an n-node list with randomly chosen data values,
+// where the .Next pointers visit nodes from low memory address to high.
+func MemoryOrderedList(n int, useCheapRand bool) *Node {
+
+	head := newNode()
+	head.Data = uint(uintptr(unsafe.Pointer(head)))
+	tail := head
+
+	// Append new *Node to end of list - this will create
+	// a list that has blocks of nodes in descending address order
+	for i := 1; i < n; i++ {
+		nn := newNode()
+		nn.Data = uint(uintptr(unsafe.Pointer(nn)))
+		tail.Next = nn
+		tail = tail.Next
+	}
+
+	// sort all nodes by address, so that even blocks of nodes are
+	// ordered by ascending address.
+	head = listsort.RecursiveMergeSort(head)
+	return RerandomizeList(head, useCheapRand)
+}
+
+// MemoryOrderedListBW creates an n-node list where the .Next pointers
+// visit nodes from high memory address to low. Node data values
+// are the node addresses.
+func MemoryOrderedListBW(n int, _ bool) *Node {
+
+	var head *Node
+
+	for i := 0; i < n; i++ {
+		nn := newNode()
+		nn.Data = uint(uintptr(unsafe.Pointer(nn)))
+		nn.Next = head
+		head = nn
+	}
+
+	// sort all nodes by address, so that even blocks of nodes are
+	// ordered by descending address.
+	return recursiveMergeSortReversed(head)
+}
+
+// RandomAddressedList creates an n-node list with data values 0 to n-1,
+// low-to-high, by sorting a reverse sorted list. The sort scatters
+// the .Next pointers around in memory.
+func RandomAddressedList(n int, useCheapRand bool) *Node {
+	return listsort.RecursiveMergeSort(ReverseSortedList(n, useCheapRand))
+}
+
+// RerandomizeList gives every node of a list a new randomly chosen
+// data value, without changing the list's node order.
+func RerandomizeList(head *Node, useCheapRand bool) *Node {
+	for node := head; node != nil; node = node.Next {
+		node.Data = RandomValue(useCheapRand)
+	}
+	return head
+}
+
+// RandomValue returns a value from the generator UseRand set
+// when useCheapRand is true, a crypto/rand value otherwise.
+func RandomValue(useCheapRand bool) uint {
+	var ri int
+	if useCheapRand {
+		ri = rng.Int()
+	} else {
+		mp, err := crand.Int(crand.Reader, maxInt)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ri = int(mp.Int64())
+	}
+	return uint(ri)
+}
+
+// recursiveMergeSortReversed sorts high-to-low
+func recursiveMergeSortReversed(head *Node) *Node {
+	if head.Next == nil {
+		// single node list is sorted by definiton
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	left, right := listsort.Split(head)
+
+	left = recursiveMergeSortReversed(left)
+	right = recursiveMergeSortReversed(right)
+
+	// Set h, t variables so that the loop doing the merge
+	// does not have to have a "if h == nil" check every iteration.
+	x := &right
+	if left.Data > right.Data {
+		x = &left
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	for left != nil && right != nil {
+		n := &right
+		if left.Data > right.Data {
+			n = &left
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+	}
+
+	// Either left or right are nil. If left == nil,
+	// assigning nil to t.Next is no issue.
+	t.Next = left
+	if right != nil {
+		// but if right is nil, can't assign nil to t.Next,
+		// because left was non-nil.
+		t.Next = right
+	}
+
+	return h
+}
+
+// NodeOrder returns the nodes of a list in .Next pointer order,
+// so that ResetList can put a sorted list back the way it was.
+func NodeOrder(head *Node) []*Node {
+	var order []*Node
+	for node := head; node != nil; node = node.Next {
+		order = append(order, node)
+	}
+	return order
+}
+
+// ResetList sets .Next pointers so that the nodes in order
+// form a nil-terminated list again, restoring the original
+// node order. It returns the head of the restored list.
+func ResetList(order []*Node) *Node {
+	if len(order) == 0 {
+		return nil
+	}
+	for i := 1; i < len(order); i++ {
+		order[i-1].Next = order[i]
+	}
+	order[len(order)-1].Next = nil
+	return order[0]
+}
+
+// DoublyLinked creates a doubly linked list with the same
+// data values in the same order as a singly linked list,
+// allocating the new nodes in list order.
+func DoublyLinked(head *Node) *listsort.DNode {
+	var dhead, tail *listsort.DNode
+	for node := head; node != nil; node = node.Next {
+		dn := &listsort.DNode{Data: node.Data, Prev: tail}
+		if tail == nil {
+			dhead = dn
+		} else {
+			tail.Next = dn
+		}
+		tail = dn
+	}
+	return dhead
+}
+
+// Ring closes a nil-terminated list into a circular list,
+// returning the node at position start, counting from 0 at head,
+// as the ring's start node. A presorted list with a start
+// other than 0 becomes a ring that's sorted, but rotated.
+func Ring(head *Node, start int) *Node {
+	if head == nil {
+		return nil
+	}
+	startNode := head
+	last := head
+	for i := 0; last.Next != nil; i++ {
+		if i < start {
+			startNode = startNode.Next
+		}
+		last = last.Next
+	}
+	last.Next = head
+	return startNode
+}
@@ -0,0 +1,94 @@
+package listgen
+
+import (
+	"sort"
+)
+
+// Disorder says how far a list's .Next pointer order strays from
+// ascending memory address order. The zero value means no disorder:
+// every .Next pointer points to the adjacent node at the next
+// higher address. The three kinds of disorder apply in the order
+// Block, MaxJump, Fraction, and combine.
+type Disorder struct {
+	// Block shuffles list order within each run of Block nodes
+	// that are adjacent in memory, so nodes stay close to where
+	// they'd be, but not in order. A Block of 0 or 1 shuffles nothing.
+	Block int
+	// MaxJump moves every node up to MaxJump list positions away
+	// from its position in memory order.
+	MaxJump int
+	// Fraction, from 0 to 1, is the fraction of nodes moved
+	// to randomly chosen list positions, anywhere in the list.
+	// A Fraction of 1 puts nodes in random memory order.
+	Fraction float64
+}
+
+// LocalityList returns a list generator that places a list's n nodes
+// in one slab, adjacent in memory like nodes of an Arena, the size
+// SetNodeSize last set apart, and links them in an order with the disorder d describes.
+// The nodes get randomly chosen data values.
+func LocalityList(d Disorder) func(int, bool) *Node {
+	return func(n int, useCheapRand bool) *Node {
+		if n == 0 {
+			return nil
+		}
+		slab := makeSlab(n)
+
+		// order[i] is the slab index of the node at list position i
+		order := make([]int, n)
+		for i := range order {
+			order[i] = i
+		}
+
+		if d.Block > 1 {
+			for start := 0; start < n; start += d.Block {
+				block := order[start:min(start+d.Block, n)]
+				rng.Shuffle(len(block), func(i, j int) {
+					block[i], block[j] = block[j], block[i]
+				})
+			}
+		}
+
+		if d.MaxJump > 0 {
+			// Sorting positions by i plus a random amount
+			// from 0 to MaxJump moves no node more than MaxJump.
+			keys := make([]float64, n)
+			for i := range keys {
+				keys[i] = float64(i) + rng.Float64()*float64(d.MaxJump)
+			}
+			sort.Sort(byKey{order, keys})
+		}
+
+		if d.Fraction > 0 {
+			// Shuffle the nodes at a randomly chosen
+			// Fraction of list positions among themselves.
+			moved := rng.Perm(n)[:int(min(d.Fraction, 1)*float64(n))]
+			for i := len(moved) - 1; i > 0; i-- {
+				j := rng.IntN(i + 1)
+				order[moved[i]], order[moved[j]] = order[moved[j]], order[moved[i]]
+			}
+		}
+
+		for i := 0; i < n-1; i++ {
+			slab.at(order[i]).Next = slab.at(order[i+1])
+		}
+		for i := range n {
+			slab.at(i).Data = RandomValue(useCheapRand)
+		}
+
+		return slab.at(order[0])
+	}
+}
+
+// byKey sorts slab indexes by keys, keeping them paired.
+type byKey struct {
+	order []int
+	keys  []float64
+}
+
+func (b byKey) Len() int           { return len(b.order) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.order[i], b.order[j] = b.order[j], b.order[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
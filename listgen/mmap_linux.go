@@ -0,0 +1,126 @@
+package listgen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// hugePageSize is the size of an x86_64 or arm64 transparent huge page.
+const hugePageSize = 2 << 20
+
+// MmapArena allocates list nodes from anonymous memory mapped with
+// syscall.Mmap instead of the Go heap, so that madvise(2) can ask
+// the kernel to back the nodes with transparent huge pages, or not.
+// The garbage collector doesn't look at mmap'ed memory: nodes from
+// an MmapArena have to point only to other nodes from the same arena.
+type MmapArena struct {
+	mem    []byte
+	nodes  slab
+	next   int
+	advice string
+}
+
+// NewMmapArena maps memory for count nodes of the size SetNodeSize
+// last set, exactly that many bytes apart. The advice "huge"
+// applies MADV_HUGEPAGE to the memory, "nohuge" applies
+// MADV_NOHUGEPAGE, "none" leaves it to the kernel's default.
+func NewMmapArena(count int, advice string) (*MmapArena, error) {
+	var madvise int
+	switch advice {
+	case "huge":
+		madvise = syscall.MADV_HUGEPAGE
+	case "nohuge":
+		madvise = syscall.MADV_NOHUGEPAGE
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown huge page advice %q, want huge, nohuge or none", advice)
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	// Map an extra huge page, to start the nodes on a huge page boundary.
+	size := (count*nodeSize + hugePageSize - 1) &^ (hugePageSize - 1)
+	mem, err := syscall.Mmap(-1, 0, size+hugePageSize,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return nil, fmt.Errorf("mmap %d bytes: %w", size+hugePageSize, err)
+	}
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&mem[0])) % hugePageSize); rem != 0 {
+		offset = hugePageSize - rem
+	}
+	aligned := mem[offset : offset+size]
+
+	if madvise != 0 {
+		if err := syscall.Madvise(aligned, madvise); err != nil {
+			syscall.Munmap(mem)
+			return nil, fmt.Errorf("madvise %s: %w", advice, err)
+		}
+	}
+
+	return &MmapArena{
+		mem:    mem,
+		nodes:  slab{base: unsafe.Pointer(&aligned[0]), len: count, stride: nodeSize},
+		advice: advice,
+	}, nil
+}
+
+// Alloc returns the next unused node of the arena.
+// It panics if the arena has no unused nodes left.
+func (a *MmapArena) Alloc() *Node {
+	if a.next == a.nodes.len {
+		panic(fmt.Sprintf("mmap arena of %d nodes used up", a.nodes.len))
+	}
+	node := a.nodes.at(a.next)
+	a.next++
+	return node
+}
+
+// Reset zeroes every node the arena has handed out,
+// and has Alloc start over at the first node.
+// Lists built from the arena's nodes are garbage after Reset.
+func (a *MmapArena) Reset() {
+	a.nodes.clear(a.next)
+	a.next = 0
+}
+
+// Close unmaps the arena's memory. Touching any of the arena's nodes
+// after Close is a segmentation fault.
+func (a *MmapArena) Close() error {
+	a.nodes = slab{}
+	a.next = 0
+	return syscall.Munmap(a.mem)
+}
+
+// UseMmapArena makes the list generators allocate nodes from arena.
+// UseMmapArena(nil) goes back to allocating each node separately,
+// at the size SetNodeSize last set, and leaves any Pool in use.
+func UseMmapArena(arena *MmapArena) {
+	if arena == nil {
+		arenaNode = nil
+		return
+	}
+	arenaNode = arena.Alloc
+}
+
+// THPEnabled returns the system's transparent huge page setting,
+// "always", "madvise" or "never", from
+// /sys/kernel/mm/transparent_hugepage/enabled,
+// or "unknown" if it can't read the setting.
+func THPEnabled() string {
+	buf, err := os.ReadFile("/sys/kernel/mm/transparent_hugepage/enabled")
+	if err != nil {
+		return "unknown"
+	}
+	// the file looks like "always [madvise] never"
+	start := bytes.IndexByte(buf, '[')
+	end := bytes.IndexByte(buf, ']')
+	if start < 0 || end < start {
+		return "unknown"
+	}
+	return string(buf[start+1 : end])
+}
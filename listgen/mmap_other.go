@@ -0,0 +1,29 @@
+//go:build !linux
+
+package listgen
+
+import "errors"
+
+// MmapArena allocates list nodes from memory mapped with syscall.Mmap.
+// It's Linux-only: madvise(2) huge page advice is a Linux feature.
+type MmapArena struct{}
+
+// NewMmapArena always fails on systems other than Linux.
+func NewMmapArena(count int, advice string) (*MmapArena, error) {
+	return nil, errors.New("mmap arena only on Linux")
+}
+
+// Alloc is never called, NewMmapArena never returns an arena.
+func (a *MmapArena) Alloc() *Node { return nil }
+
+// Reset does nothing.
+func (a *MmapArena) Reset() {}
+
+// Close does nothing.
+func (a *MmapArena) Close() error { return nil }
+
+// UseMmapArena does nothing.
+func UseMmapArena(arena *MmapArena) {}
+
+// THPEnabled returns "unknown", there are no transparent huge pages.
+func THPEnabled() string { return "unknown" }
@@ -0,0 +1,106 @@
+package listgen
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// heapNode allocates a single node of the size SetNodeSize last set.
+var heapNode = func() *Node { return &Node{} }
+
+// arenaNode allocates nodes from the arena in use, nil for no arena.
+var arenaNode func() *Node
+
+// newNode allocates every node the list generators create,
+// from the Pool UsePool set, if any, otherwise with allocNode.
+func newNode() *Node {
+	if usingPool != nil {
+		return usingPool.Alloc()
+	}
+	return allocNode()
+}
+
+// allocNode allocates a node from the arena UseArena or
+// UseMmapArena set, if any, otherwise with heapNode.
+// A Pool allocates the nodes it doesn't have with allocNode.
+func allocNode() *Node {
+	if arenaNode != nil {
+		return arenaNode()
+	}
+	return heapNode()
+}
+
+// nodeSize is the size in bytes of the nodes newNode allocates.
+var nodeSize = int(unsafe.Sizeof(Node{}))
+
+// nodeType is Node, or a Node followed by padding out to nodeSize bytes.
+var nodeType = reflect.TypeOf(Node{})
+
+// SetNodeSize makes the list generators allocate nodes of size bytes:
+// a Node followed by padding that none of the sorts touch, like
+// the payload of a real list element. Bigger nodes mean fewer nodes
+// per cache line and per page. The size has to be a multiple of
+// Node's alignment, and at least the size of a Node. Go rounds
+// every allocation up to one of its size classes, so sizes that
+// are size classes, 16, 32, 48, 64, 80, 96, 112, 128... 1024,
+// space nodes exactly size bytes apart. Arenas created after
+// SetNodeSize space their nodes exactly size bytes apart.
+func SetNodeSize(size int) error {
+	base := int(unsafe.Sizeof(Node{}))
+	align := int(unsafe.Alignof(Node{}))
+	if size < base || size%align != 0 {
+		return fmt.Errorf("node size %d bytes: has to be a multiple of %d, at least %d", size, align, base)
+	}
+
+	nodeSize = size
+	if size == base {
+		nodeType = reflect.TypeOf(Node{})
+		heapNode = func() *Node { return &Node{} }
+		return nil
+	}
+
+	// A struct type built at run time, so that the garbage collector
+	// knows where the .Next pointer is, and that the padding isn't pointers.
+	padded := reflect.StructOf([]reflect.StructField{
+		{Name: "Node", Type: reflect.TypeOf(Node{})},
+		{Name: "Pad", Type: reflect.ArrayOf(size-base, reflect.TypeOf(byte(0)))},
+	})
+	nodeType = padded
+	heapNode = func() *Node {
+		return (*Node)(reflect.New(padded).UnsafePointer())
+	}
+	return nil
+}
+
+// NodeSize returns the size in bytes of the nodes the list generators allocate.
+func NodeSize() int {
+	return nodeSize
+}
+
+// slab is a run of nodes adjacent in memory, stride bytes apart.
+type slab struct {
+	base   unsafe.Pointer
+	len    int
+	stride int
+}
+
+// makeSlab allocates a slab of n zeroed nodes of the size
+// SetNodeSize last set.
+func makeSlab(n int) slab {
+	nodes := reflect.MakeSlice(reflect.SliceOf(nodeType), n, n)
+	return slab{base: nodes.UnsafePointer(), len: n, stride: nodeSize}
+}
+
+// at returns the slab's i'th node.
+func (s slab) at(i int) *Node {
+	return (*Node)(unsafe.Add(s.base, i*s.stride))
+}
+
+// clear zeroes the slab's first n nodes. Nothing writes the
+// padding after each Node, so it's still zero.
+func (s slab) clear(n int) {
+	for i := range n {
+		*s.at(i) = Node{}
+	}
+}
@@ -0,0 +1,58 @@
+package listgen
+
+// Pool keeps the nodes of freed lists on a free list, chained
+// together through their .Next pointers, and hands them out again
+// before allocating any new nodes, like mergetest.c's freeNodeList.
+// Hits counts nodes Alloc re-used from the free list, Misses counts
+// nodes Alloc had to allocate. The zero value is an empty Pool.
+type Pool struct {
+	Hits   int
+	Misses int
+
+	free     *Node
+	freeSize int
+}
+
+// Alloc returns a node from the free list if there is one,
+// otherwise a newly allocated node.
+func (p *Pool) Alloc() *Node {
+	if p.free != nil {
+		node := p.free
+		p.free = node.Next
+		p.freeSize--
+		node.Next = nil
+		p.Hits++
+		return node
+	}
+	p.Misses++
+	return allocNode()
+}
+
+// Free puts every node of a nil-terminated list on the free list.
+// The list's nodes are garbage after Free.
+func (p *Pool) Free(head *Node) {
+	for head != nil {
+		next := head.Next
+		head.Data = 0
+		head.Next = p.free
+		p.free = head
+		p.freeSize++
+		head = next
+	}
+}
+
+// FreeSize returns the number of nodes on the free list.
+func (p *Pool) FreeSize() int {
+	return p.freeSize
+}
+
+// usingPool is the Pool the list generators allocate from, if any.
+var usingPool *Pool
+
+// UsePool makes the list generators allocate nodes from pool.
+// Nodes the pool has to allocate come from whatever allocation
+// SetNodeSize, UseArena or UseMmapArena set up. UsePool(nil)
+// goes back to allocating nodes that way directly.
+func UsePool(pool *Pool) {
+	usingPool = pool
+}
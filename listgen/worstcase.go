@@ -0,0 +1,107 @@
+package listgen
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// MergeTrees describes the mergesort algorithms that WorstCase
+// and BestCase know, for command line help.
+const MergeTrees = "recursive, bottomup, iterative"
+
+// mergeSplit returns a function giving the length of the left
+// (earlier nodes) list of the last merge the named algorithm does
+// sorting an n-long list. The rest of the algorithm's merges
+// follow by applying the function to the left and right lists.
+func mergeSplit(algorithm string) (func(n int) int, error) {
+	switch algorithm {
+	case "recursive":
+		// listsort.RecursiveMergeSort's rabbit and turtle
+		// leave the shorter half on the left
+		return func(n int) int { return n / 2 }, nil
+	case "bottomup", "iterative":
+		// listsort.BUMergesort's array of 2^i-long lists and
+		// listsort.Mergesort's passes of doubling k both merge
+		// the biggest power of 2 lists before the rest of the list
+		return func(n int) int { return 1 << (bits.Len(uint(n-1)) - 1) }, nil
+	}
+	return nil, fmt.Errorf("unknown mergesort %q, want one of %s", algorithm, MergeTrees)
+}
+
+// WorstCase returns a function giving the data values, 0 to n-1,
+// of an n-long list that makes the named mergesort do the most
+// comparisons possible. Every merge of an a-long and b-long list
+// makes a+b-1 comparisons, because the last two nodes merged come
+// from different lists.
+func WorstCase(algorithm string) (func(n int) []uint, error) {
+	return unmergedValues(algorithm, true)
+}
+
+// BestCase returns a function giving the data values, 0 to n-1,
+// of an n-long list that makes the named mergesort do the fewest
+// comparisons possible. Every merge of an a-long and b-long list
+// makes min(a, b) comparisons, because the shorter list has all
+// the smaller data values.
+func BestCase(algorithm string) (func(n int) []uint, error) {
+	return unmergedValues(algorithm, false)
+}
+
+func unmergedValues(algorithm string, worst bool) (func(n int) []uint, error) {
+	split, err := mergeSplit(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return func(n int) []uint {
+		v := make([]uint, n)
+		for i := range v {
+			v[i] = uint(i)
+		}
+		unmerge(v, make([]uint, n), split, worst)
+		return v
+	}, nil
+}
+
+// unmerge rearranges the ascending values in v into the order
+// of a list that, merging the algorithm's way, ends up as v.
+// Starting from the last merge, it deals out values to the left
+// and right lists, then works on each of those lists the same way.
+func unmerge(v, scratch []uint, split func(int) int, worst bool) {
+	n := len(v)
+	if n < 2 {
+		return
+	}
+	s := split(n)
+	left, right := scratch[:s], scratch[s:n]
+
+	if worst {
+		// Deal from the biggest value down, alternating lists,
+		// starting with the longer list, so neither list runs
+		// out until the merge's last comparison.
+		i, j := len(left), len(right)
+		toLeft := i >= j
+		for k := n - 1; k >= 0; k-- {
+			if j == 0 || (i > 0 && toLeft) {
+				i--
+				left[i] = v[k]
+			} else {
+				j--
+				right[j] = v[k]
+			}
+			toLeft = !toLeft
+		}
+	} else {
+		// The shorter list gets the smallest values,
+		// so the merge uses it up first.
+		if len(left) <= len(right) {
+			copy(left, v[:s])
+			copy(right, v[s:])
+		} else {
+			copy(right, v[:n-s])
+			copy(left, v[n-s:])
+		}
+	}
+
+	copy(v, scratch[:n])
+	unmerge(v[:s], scratch[:s], split, worst)
+	unmerge(v[s:], scratch[s:n], split, worst)
+}
@@ -0,0 +1,77 @@
+package listsort
+
+// BUMergesort - transliteration of Wikipedia's "Bottom up implementation with lists",
+// https://en.wikipedia.org/wiki/Merge_sort#Bottom-up_implementation_using_lists
+func BUMergesort(head *Node) *Node {
+	if head == nil {
+		return nil
+	}
+	// Can pass 1-length lists to the rest of the function,
+	// because array[0] is 2^0 or 1 in size
+
+	var array [32]*Node
+	var result, next *Node
+	var i int
+
+	result = head
+
+	for result != nil {
+		next = result.Next
+		result.Next = nil
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = Merge(array[i], result)
+			array[i] = nil
+		}
+		if i == 32 {
+			i--
+		}
+		array[i] = result
+		result = next
+	}
+
+	result = nil
+	for i = 0; i < 32; i++ {
+		result = Merge(array[i], result)
+	}
+
+	return result
+}
+
+// Merge combines two sorted, nil-terminated lists into
+// a single sorted list. Either list can be nil.
+// Nodes of p come before nodes of q with equal data values,
+// so passing the list of earlier nodes as p keeps a sort stable.
+func Merge(p *Node, q *Node) *Node {
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+
+	x := &q
+	if p.Data <= q.Data {
+		x = &p
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	for p != nil && q != nil {
+		n := &q
+		if p.Data <= q.Data {
+			n = &p
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+	}
+
+	t.Next = p
+	if q != nil {
+		t.Next = q
+	}
+
+	return h
+}
@@ -0,0 +1,233 @@
+package listsort
+
+// DNode is an element of a doubly linked list.
+type DNode struct {
+	Data uint
+	Prev *DNode
+	Next *DNode
+}
+
+// DoublyMergesort is Mergesort for doubly linked lists.
+// Like the other doubly linked list sorts, it only
+// rewrites .Next pointers while merging, then puts
+// every .Prev pointer right in one pass over the
+// sorted list, the way the Linux kernel's list_sort does.
+func DoublyMergesort(head *DNode) *DNode {
+	if head == nil {
+		return nil
+	}
+
+	var hd, tl *DNode
+	appnd := func(n *DNode) {
+		if hd == nil {
+			hd = n
+			tl = n
+			return
+		}
+		tl.Next = n
+		tl = n
+	}
+
+	p := head
+	mergecount := 2 // just to pass the first for-test
+
+	for k := 1; mergecount > 1; k *= 2 {
+
+		mergecount = 0
+
+		for p != nil {
+
+			psize := 0
+			q := p
+			for i := 0; q != nil && i < k; i++ {
+				psize++
+				q = q.Next
+			}
+
+			qsize := psize
+
+			for psize > 0 && qsize > 0 && q != nil {
+				if p.Data <= q.Data {
+					appnd(p)
+					p = p.Next
+					psize--
+					continue
+				}
+				appnd(q)
+				q = q.Next
+				qsize--
+			}
+
+			for ; psize > 0 && p != nil; psize-- {
+				appnd(p)
+				p = p.Next
+			}
+
+			for ; qsize > 0 && q != nil; qsize-- {
+				appnd(q)
+				q = q.Next
+			}
+
+			p = q
+
+			mergecount++
+		}
+
+		p = hd
+		head = hd
+
+		hd = nil
+		tl.Next = nil
+		tl = nil
+	}
+
+	return FixPrev(head)
+}
+
+// DoublyRecursiveMergeSort is RecursiveMergeSort for doubly linked lists.
+func DoublyRecursiveMergeSort(head *DNode) *DNode {
+	return FixPrev(doublyRecursiveMergeSort(head))
+}
+
+func doublyRecursiveMergeSort(head *DNode) *DNode {
+	if head == nil || head.Next == nil {
+		return head
+	}
+
+	rabbit, turtle := head.Next, &head
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+	right := *turtle
+	*turtle = nil
+
+	left := doublyRecursiveMergeSort(head)
+	right = doublyRecursiveMergeSort(right)
+
+	return doublyMerge(left, right)
+}
+
+// DoublyBUMergesort is BUMergesort for doubly linked lists.
+func DoublyBUMergesort(head *DNode) *DNode {
+	if head == nil {
+		return nil
+	}
+
+	var array [32]*DNode
+	var result, next *DNode
+	var i int
+
+	result = head
+
+	for result != nil {
+		next = result.Next
+		result.Next = nil
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = doublyMerge(array[i], result)
+			array[i] = nil
+		}
+		if i == 32 {
+			i--
+		}
+		array[i] = result
+		result = next
+	}
+
+	result = nil
+	for i = 0; i < 32; i++ {
+		result = doublyMerge(array[i], result)
+	}
+
+	return FixPrev(result)
+}
+
+// doublyMerge is Merge for doubly linked lists,
+// leaving .Prev pointers for FixPrev to set.
+func doublyMerge(p *DNode, q *DNode) *DNode {
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+
+	x := &q
+	if p.Data <= q.Data {
+		x = &p
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	for p != nil && q != nil {
+		n := &q
+		if p.Data <= q.Data {
+			n = &p
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+	}
+
+	t.Next = p
+	if q != nil {
+		t.Next = q
+	}
+
+	return h
+}
+
+// FixPrev walks a doubly linked list by its .Next pointers,
+// setting every node's .Prev pointer to the node before it.
+// It returns head, so that a sort can end with "return FixPrev(head)".
+func FixPrev(head *DNode) *DNode {
+	var prev *DNode
+	for node := head; node != nil; node = node.Next {
+		node.Prev = prev
+		prev = node
+	}
+	return head
+}
+
+// IsDoublySorted walks a doubly linked list forward, then backward
+// from its last node, returning the number of nodes and whether
+// the list is sorted low-to-high in both directions. If the list
+// isn't sorted, or a .Prev pointer doesn't point to the node
+// before it, the returned count is the position of the first
+// problem node, counting from the head.
+func IsDoublySorted(head *DNode) (int, bool) {
+	if head == nil {
+		return 0, true
+	}
+	if head.Prev != nil {
+		return 0, false
+	}
+
+	sz := 1
+	tail := head
+	for ; tail.Next != nil; tail = tail.Next {
+		if tail.Data > tail.Next.Data || tail.Next.Prev != tail {
+			return sz, false
+		}
+		sz++
+	}
+
+	// Walking backward has to find the same nodes in reverse order,
+	// and end at head.
+	pos := sz - 1
+	for node := tail; node.Prev != nil; node = node.Prev {
+		if node.Prev.Data > node.Data {
+			return pos, false
+		}
+		pos--
+	}
+	if pos != 0 {
+		return pos, false
+	}
+
+	return sz, true
+}
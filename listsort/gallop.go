@@ -0,0 +1,185 @@
+package listsort
+
+// GallopMerge is Merge, except that once one list has supplied
+// minGallop nodes in a row, it "gallops" along that list: instead
+// of comparing each of that list's nodes to the other list's head,
+// it compares nodes 1, 2, 4, 8... nodes along, then binary searches
+// between the last two of those, to find how many nodes it can
+// append to the merged list at once. Galloping still walks the
+// nodes, but it can save a lot of comparisons on partly ordered data.
+// A minGallop of 0 or less means never gallop.
+func GallopMerge(p *Node, q *Node, minGallop int) *Node {
+	if minGallop <= 0 {
+		return Merge(p, q)
+	}
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+
+	var h *Node
+	t := &h
+	var pWins, qWins int
+
+	for p != nil && q != nil {
+		if pWins >= minGallop {
+			pWins = 0
+			if count, last := gallop(p, q.Data, false); count > 0 {
+				*t = p
+				t = &last.Next
+				p = last.Next
+				if p == nil {
+					break
+				}
+			}
+			// p's head sorts after q's head, no need to compare them.
+			*t = q
+			t = &q.Next
+			q = q.Next
+			qWins = 1
+			continue
+		}
+		if qWins >= minGallop {
+			qWins = 0
+			if count, last := gallop(q, p.Data, true); count > 0 {
+				*t = q
+				t = &last.Next
+				q = last.Next
+				if q == nil {
+					break
+				}
+			}
+			// q's head sorts after p's head, no need to compare them.
+			*t = p
+			t = &p.Next
+			p = p.Next
+			pWins = 1
+			continue
+		}
+
+		if p.Data <= q.Data {
+			*t = p
+			t = &p.Next
+			p = p.Next
+			pWins++
+			qWins = 0
+			continue
+		}
+		*t = q
+		t = &q.Next
+		q = q.Next
+		qWins++
+		pWins = 0
+	}
+
+	*t = p
+	if q != nil {
+		*t = q
+	}
+
+	return h
+}
+
+// gallop counts the nodes at the beginning of list with data values
+// less than or equal to value, or strictly less than value if strict
+// is set, returning the count and the last of those nodes.
+// Nodes of the earlier list gallop past equal data values, nodes of
+// the later list don't, so that galloping merges stay stable.
+func gallop(list *Node, value uint, strict bool) (int, *Node) {
+	ahead := func(data uint) bool {
+		if strict {
+			return data < value
+		}
+		return data <= value
+	}
+
+	if !ahead(list.Data) {
+		return 0, nil
+	}
+
+	// last is the furthest node known to sort ahead of value,
+	// lastOffset is its position in list.
+	last, lastOffset := list, 0
+
+	for step := 1; ; step *= 2 {
+		probe := last
+		i := 0
+		for ; i < step && probe.Next != nil; i++ {
+			probe = probe.Next
+		}
+		if i == 0 {
+			// every node of list sorts ahead of value
+			return lastOffset + 1, last
+		}
+		if ahead(probe.Data) {
+			last, lastOffset = probe, lastOffset+i
+			continue
+		}
+
+		// Binary search the nodes between last, which sorts ahead
+		// of value, and probe, i nodes further on, which doesn't.
+		lo, hi := 0, i
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			node := last
+			for j := lo; j < mid; j++ {
+				node = node.Next
+			}
+			if ahead(node.Data) {
+				last, lo = node, mid
+			} else {
+				hi = mid
+			}
+		}
+		return lastOffset + lo + 1, last
+	}
+}
+
+// RecursiveMergeSortGalloping is RecursiveMergeSort
+// with GallopMerge in place of the in-line merge.
+func RecursiveMergeSortGalloping(head *Node, minGallop int) *Node {
+	if head == nil || head.Next == nil {
+		return head
+	}
+	left, right := Split(head)
+	left = RecursiveMergeSortGalloping(left, minGallop)
+	right = RecursiveMergeSortGalloping(right, minGallop)
+	return GallopMerge(left, right, minGallop)
+}
+
+// BUMergesortGalloping is BUMergesort with GallopMerge in place of Merge.
+func BUMergesortGalloping(head *Node, minGallop int) *Node {
+	if head == nil {
+		return nil
+	}
+
+	var array [32]*Node
+	var result, next *Node
+	var i int
+
+	result = head
+
+	for result != nil {
+		next = result.Next
+		result.Next = nil
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = GallopMerge(array[i], result, minGallop)
+			array[i] = nil
+		}
+		if i == 32 {
+			i--
+		}
+		array[i] = result
+		result = next
+	}
+
+	result = nil
+	for i = 0; i < 32; i++ {
+		result = GallopMerge(array[i], result, minGallop)
+	}
+
+	return result
+}
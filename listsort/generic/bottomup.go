@@ -0,0 +1,77 @@
+package generic
+
+// BUMergesort - Wikipedia's "Bottom up implementation with lists",
+// ordering nodes by cmp.
+func BUMergesort[T any](head *Node[T], cmp func(a, b T) int) *Node[T] {
+	if head == nil {
+		return nil
+	}
+	// Can pass 1-length lists to the rest of the function,
+	// because array[0] is 2^0 or 1 in size
+
+	var array [32]*Node[T]
+	var result, next *Node[T]
+	var i int
+
+	result = head
+
+	for result != nil {
+		next = result.Next
+		result.Next = nil
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = Merge(array[i], result, cmp)
+			array[i] = nil
+		}
+		if i == 32 {
+			i--
+		}
+		array[i] = result
+		result = next
+	}
+
+	result = nil
+	for i = 0; i < 32; i++ {
+		result = Merge(array[i], result, cmp)
+	}
+
+	return result
+}
+
+// Merge combines two lists sorted by cmp into
+// a single sorted list. Either list can be nil.
+// Nodes of p come before nodes of q that cmp finds equal,
+// so passing the list of earlier nodes as p keeps a sort stable.
+func Merge[T any](p *Node[T], q *Node[T], cmp func(a, b T) int) *Node[T] {
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+
+	x := &q
+	if cmp(p.Data, q.Data) <= 0 {
+		x = &p
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	for p != nil && q != nil {
+		n := &q
+		if cmp(p.Data, q.Data) <= 0 {
+			n = &p
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+	}
+
+	t.Next = p
+	if q != nil {
+		t.Next = q
+	}
+
+	return h
+}
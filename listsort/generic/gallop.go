@@ -0,0 +1,174 @@
+package generic
+
+// GallopMerge is Merge, except that once one list has supplied
+// minGallop nodes in a row, it gallops along that list, comparing
+// nodes 1, 2, 4, 8... nodes along, then binary searching between
+// the last two of those, to append many nodes at once.
+// A minGallop of 0 or less means never gallop.
+func GallopMerge[T any](p *Node[T], q *Node[T], minGallop int, cmp func(a, b T) int) *Node[T] {
+	if minGallop <= 0 {
+		return Merge(p, q, cmp)
+	}
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+
+	var h *Node[T]
+	t := &h
+	var pWins, qWins int
+
+	for p != nil && q != nil {
+		if pWins >= minGallop {
+			pWins = 0
+			if count, last := gallop(p, q.Data, false, cmp); count > 0 {
+				*t = p
+				t = &last.Next
+				p = last.Next
+				if p == nil {
+					break
+				}
+			}
+			// p's head sorts after q's head, no need to compare them.
+			*t = q
+			t = &q.Next
+			q = q.Next
+			qWins = 1
+			continue
+		}
+		if qWins >= minGallop {
+			qWins = 0
+			if count, last := gallop(q, p.Data, true, cmp); count > 0 {
+				*t = q
+				t = &last.Next
+				q = last.Next
+				if q == nil {
+					break
+				}
+			}
+			// q's head sorts after p's head, no need to compare them.
+			*t = p
+			t = &p.Next
+			p = p.Next
+			pWins = 1
+			continue
+		}
+
+		if cmp(p.Data, q.Data) <= 0 {
+			*t = p
+			t = &p.Next
+			p = p.Next
+			pWins++
+			qWins = 0
+			continue
+		}
+		*t = q
+		t = &q.Next
+		q = q.Next
+		qWins++
+		pWins = 0
+	}
+
+	*t = p
+	if q != nil {
+		*t = q
+	}
+
+	return h
+}
+
+// gallop counts the nodes at the beginning of list that cmp finds
+// less than or equal to value, or strictly less than value if strict
+// is set, returning the count and the last of those nodes.
+func gallop[T any](list *Node[T], value T, strict bool, cmp func(a, b T) int) (int, *Node[T]) {
+	ahead := func(data T) bool {
+		if strict {
+			return cmp(data, value) < 0
+		}
+		return cmp(data, value) <= 0
+	}
+
+	if !ahead(list.Data) {
+		return 0, nil
+	}
+
+	last, lastOffset := list, 0
+
+	for step := 1; ; step *= 2 {
+		probe := last
+		i := 0
+		for ; i < step && probe.Next != nil; i++ {
+			probe = probe.Next
+		}
+		if i == 0 {
+			return lastOffset + 1, last
+		}
+		if ahead(probe.Data) {
+			last, lastOffset = probe, lastOffset+i
+			continue
+		}
+
+		lo, hi := 0, i
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			node := last
+			for j := lo; j < mid; j++ {
+				node = node.Next
+			}
+			if ahead(node.Data) {
+				last, lo = node, mid
+			} else {
+				hi = mid
+			}
+		}
+		return lastOffset + lo + 1, last
+	}
+}
+
+// RecursiveMergeSortGalloping is RecursiveMergeSort with GallopMerge.
+func RecursiveMergeSortGalloping[T any](head *Node[T], minGallop int, cmp func(a, b T) int) *Node[T] {
+	if head == nil || head.Next == nil {
+		return head
+	}
+	left, right := split(head)
+	left = RecursiveMergeSortGalloping(left, minGallop, cmp)
+	right = RecursiveMergeSortGalloping(right, minGallop, cmp)
+	return GallopMerge(left, right, minGallop, cmp)
+}
+
+// BUMergesortGalloping is BUMergesort with GallopMerge in place of Merge.
+func BUMergesortGalloping[T any](head *Node[T], minGallop int, cmp func(a, b T) int) *Node[T] {
+	if head == nil {
+		return nil
+	}
+
+	var array [32]*Node[T]
+	var result, next *Node[T]
+	var i int
+
+	result = head
+
+	for result != nil {
+		next = result.Next
+		result.Next = nil
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = GallopMerge(array[i], result, minGallop, cmp)
+			array[i] = nil
+		}
+		if i == 32 {
+			i--
+		}
+		array[i] = result
+		result = next
+	}
+
+	result = nil
+	for i = 0; i < 32; i++ {
+		result = GallopMerge(array[i], result, minGallop, cmp)
+	}
+
+	return result
+}
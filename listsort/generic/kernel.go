@@ -0,0 +1,45 @@
+package generic
+
+// ListSort is the port of the Linux kernel's list_sort,
+// ordering nodes by cmp.
+func ListSort[T any](head *Node[T], cmp func(a, b T) int) *Node[T] {
+	if head == nil {
+		return nil
+	}
+
+	var pending [64]*Node[T]
+	var npending int
+
+	list := head
+	for count := uint64(0); list != nil; count++ {
+		// Find the least significant clear bit in count.
+		tail := npending - 1
+		bits := count
+		for ; bits&1 == 1; bits >>= 1 {
+			tail--
+		}
+
+		// Merge the two pending lists at tail, older list first.
+		if bits != 0 {
+			pending[tail-1] = Merge(pending[tail-1], pending[tail], cmp)
+			copy(pending[tail:npending-1], pending[tail+1:npending])
+			npending--
+			pending[npending] = nil
+		}
+
+		// Move one node from the list to pending.
+		next := list.Next
+		list.Next = nil
+		pending[npending] = list
+		npending++
+		list = next
+	}
+
+	// Merge together all the pending lists, newest to oldest.
+	list = pending[npending-1]
+	for i := npending - 2; i >= 0; i-- {
+		list = Merge(pending[i], list, cmp)
+	}
+
+	return list
+}
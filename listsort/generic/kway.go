@@ -0,0 +1,118 @@
+package generic
+
+// kwayEntry is a min-heap element for KWayMerge. The index
+// of the list the node came from breaks ties between nodes
+// cmp finds equal, keeping the merge stable.
+type kwayEntry[T any] struct {
+	node *Node[T]
+	list int
+}
+
+func less[T any](e, f kwayEntry[T], cmp func(a, b T) int) bool {
+	c := cmp(e.node.Data, f.node.Data)
+	return c < 0 || (c == 0 && e.list < f.list)
+}
+
+// KWayMerge combines any number of lists sorted by cmp into
+// a single sorted list, using a binary min-heap of the lists' head nodes.
+// Nodes of lists earlier in the lists slice come before nodes
+// of later lists that cmp finds equal. Any of the lists can be nil.
+func KWayMerge[T any](lists []*Node[T], cmp func(a, b T) int) *Node[T] {
+	heap := make([]kwayEntry[T], 0, len(lists))
+	for i, list := range lists {
+		if list != nil {
+			heap = append(heap, kwayEntry[T]{node: list, list: i})
+		}
+	}
+	for i := len(heap)/2 - 1; i >= 0; i-- {
+		siftDown(heap, i, cmp)
+	}
+
+	var head *Node[T]
+	tail := &head
+
+	for len(heap) > 1 {
+		top := heap[0].node
+		*tail = top
+		tail = &top.Next
+		if top.Next != nil {
+			heap[0].node = top.Next
+		} else {
+			heap[0] = heap[len(heap)-1]
+			heap = heap[:len(heap)-1]
+		}
+		siftDown(heap, 0, cmp)
+	}
+
+	if len(heap) == 1 {
+		// Only one list left, it's already sorted.
+		*tail = heap[0].node
+	}
+
+	return head
+}
+
+func siftDown[T any](heap []kwayEntry[T], i int, cmp func(a, b T) int) {
+	for {
+		least := i
+		left := 2*i + 1
+		if left < len(heap) && less(heap[left], heap[least], cmp) {
+			least = left
+		}
+		if right := left + 1; right < len(heap) && less(heap[right], heap[least], cmp) {
+			least = right
+		}
+		if least == i {
+			return
+		}
+		heap[i], heap[least] = heap[least], heap[i]
+		i = least
+	}
+}
+
+// KWayMergeSort is a recursive mergesort that splits a list into k
+// sublists of nearly equal length, sorts each sublist, then combines
+// them with KWayMerge, ordering nodes by cmp.
+// A k less than 2 sorts as if k was 2.
+func KWayMergeSort[T any](head *Node[T], k int, cmp func(a, b T) int) *Node[T] {
+	if k < 2 {
+		k = 2
+	}
+	size := 0
+	for node := head; node != nil; node = node.Next {
+		size++
+	}
+	return kwayMergeSort(head, size, k, cmp)
+}
+
+func kwayMergeSort[T any](head *Node[T], size int, k int, cmp func(a, b T) int) *Node[T] {
+	if size < 2 {
+		return head
+	}
+
+	lists := make([]*Node[T], 0, k)
+
+	for i := 0; i < k; i++ {
+		// every sublist gets size/k nodes, the first size%k sublists
+		// get one more, so that all size nodes end up in a sublist.
+		sublistSize := size / k
+		if i < size%k {
+			sublistSize++
+		}
+		if sublistSize == 0 {
+			break
+		}
+
+		tail := head
+		for j := 1; j < sublistSize; j++ {
+			tail = tail.Next
+		}
+		next := tail.Next
+		tail.Next = nil
+
+		lists = append(lists, kwayMergeSort(head, sublistSize, k, cmp))
+		head = next
+	}
+
+	return KWayMerge(lists, cmp)
+}
@@ -0,0 +1,71 @@
+package generic
+
+// NaturalMergesort detaches runs of nodes already in cmp order,
+// then merges the runs, ordering nodes by cmp.
+func NaturalMergesort[T any](head *Node[T], cmp func(a, b T) int) *Node[T] {
+	return naturalMergesort(head, cmp, false)
+}
+
+// NaturalMergesortReversing is NaturalMergesort, except that it also
+// finds strictly descending runs, and reverses them as it detaches them.
+func NaturalMergesortReversing[T any](head *Node[T], cmp func(a, b T) int) *Node[T] {
+	return naturalMergesort(head, cmp, true)
+}
+
+func naturalMergesort[T any](head *Node[T], cmp func(a, b T) int, reverseRuns bool) *Node[T] {
+	if head == nil {
+		return nil
+	}
+
+	var array [32]*Node[T]
+	var result, next *Node[T]
+	var i int
+
+	result = head
+
+	for result != nil {
+		result, next = nextRun(result, cmp, reverseRuns)
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = Merge(array[i], result, cmp)
+			array[i] = nil
+		}
+		if i == 32 {
+			i--
+		}
+		array[i] = result
+		result = next
+	}
+
+	result = nil
+	for i = 0; i < 32; i++ {
+		result = Merge(array[i], result, cmp)
+	}
+
+	return result
+}
+
+// nextRun detaches the run of nodes at the beginning of a list,
+// returning the nil-terminated run and the rest of the list.
+func nextRun[T any](head *Node[T], cmp func(a, b T) int, reverseRuns bool) (*Node[T], *Node[T]) {
+	if reverseRuns && head.Next != nil && cmp(head.Next.Data, head.Data) < 0 {
+		var run *Node[T]
+		for p := head; ; {
+			next := p.Next
+			p.Next = run
+			run = p
+			if next == nil || cmp(next.Data, p.Data) >= 0 {
+				return run, next
+			}
+			p = next
+		}
+	}
+
+	tail := head
+	for tail.Next != nil && cmp(tail.Data, tail.Next.Data) <= 0 {
+		tail = tail.Next
+	}
+	next := tail.Next
+	tail.Next = nil
+	return head, next
+}
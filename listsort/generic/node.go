@@ -0,0 +1,36 @@
+// Package generic holds versions of the listsort mergesort variants
+// that sort lists of any data type. Instead of comparing node data
+// values with "<=", they call a comparison function cmp(a, b), which
+// returns a negative number when a sorts before b, zero when a and b
+// are equal, and a positive number when a sorts after b,
+// the same convention as cmp.Compare and slices.SortFunc.
+// Like the listsort variants, all of these sorts are stable.
+package generic
+
+// Node is an element of a linked list with data of type T
+type Node[T any] struct {
+	Data T
+	Next *Node[T]
+}
+
+// IsSorted walks a list, returning the number of nodes
+// and whether the list is in cmp order. If the list
+// isn't sorted, the returned count is the position of the
+// first out-of-order node.
+func IsSorted[T any](head *Node[T], cmp func(a, b T) int) (int, bool) {
+	if head == nil {
+		return 0, true
+	}
+	if head.Next == nil {
+		return 1, true
+	}
+	var sz int
+	for ; head.Next != nil; head = head.Next {
+		sz++
+		if cmp(head.Data, head.Next.Data) > 0 {
+			return sz, false
+		}
+	}
+	sz++ // for-loop checks head.Next, count final element on list
+	return sz, true
+}
@@ -0,0 +1,73 @@
+package generic
+
+type stackFrame[T any] struct {
+	list   *Node[T]
+	merged *Node[T]
+	next   *stackFrame[T]
+}
+
+// OwnstackMergeSort is a recursive mergesort that keeps
+// its own stack of heap-allocated frames instead of
+// using the function call stack, ordering nodes by cmp.
+func OwnstackMergeSort[T any](head *Node[T], cmp func(a, b T) int) *Node[T] {
+	if head == nil {
+		return nil
+	}
+
+	stack := &stackFrame[T]{
+		list: head,
+	}
+
+	var sorted *Node[T]
+
+	for {
+		var elem *stackFrame[T]
+
+		elem, stack = stack, stack.next
+
+		if elem.list == nil && stack == nil {
+			sorted = elem.merged
+			break
+		}
+
+		if elem.list != nil && elem.list.Next == nil {
+			// "recursion" has bottomed out at 1-node list
+			elem.merged, elem.list = elem.list, nil
+			elem.next, stack = stack, elem
+			continue
+		}
+
+		if elem.merged != nil {
+			// a merged sublist has "returned"
+
+			tmp := stack
+			stack = stack.next
+
+			if tmp.merged == nil {
+				elem.next, stack = stack, elem
+				tmp.next, stack = stack, tmp
+				continue
+			}
+
+			// both tmp and elem contain merged sublists
+
+			elem.merged = Merge(elem.merged, tmp.merged, cmp)
+			elem.next, stack = stack, elem
+			// discarding tmp
+			continue
+		}
+
+		// still "recursing"
+		left, right := split(elem.list)
+		stack = &stackFrame[T]{
+			list: left,
+			next: stack,
+		}
+		stack = &stackFrame[T]{
+			list: right,
+			next: stack,
+		}
+	}
+
+	return sorted
+}
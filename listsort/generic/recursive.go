@@ -0,0 +1,80 @@
+package generic
+
+// RecursiveMergeSort is the purely recursive mergesort,
+// ordering nodes by cmp.
+func RecursiveMergeSort[T any](head *Node[T], cmp func(a, b T) int) *Node[T] {
+	if head == nil || head.Next == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	// Setting rabbit and turtle like this means we split an
+	// odd-length-list (head) into lists of length n (right)
+	// and n+1 (left).
+	rabbit, turtle := head.Next, &head
+
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+
+	right := *turtle
+	*turtle = nil
+
+	left := RecursiveMergeSort(head, cmp)
+	right = RecursiveMergeSort(right, cmp)
+
+	// Set h, t variables so that the loop doing the merge
+	// does not have to have a "if h == nil" check every iteration.
+	x := &right
+	if cmp(left.Data, right.Data) <= 0 {
+		x = &left
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	// left and right are either equal in length, or right is one
+	// node longer, but the "<=" check might take more from one list
+	// than the other. Have to check both for nil.
+	for left != nil && right != nil {
+		n := &right
+		if cmp(left.Data, right.Data) <= 0 {
+			n = &left
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+	}
+
+	// Either left or right are nil. If left == nil,
+	// assigning nil to t.Next is no issue.
+	t.Next = left
+	if right != nil {
+		// but if right is nil, can't assign nil to t.Next,
+		// because left was non-nil.
+		t.Next = right
+	}
+
+	return h
+}
+
+// split divides a list of at least 2 nodes into two nil-terminated
+// lists, using the same rabbit and turtle walk as RecursiveMergeSort.
+func split[T any](head *Node[T]) (*Node[T], *Node[T]) {
+	rabbit, turtle := head.Next, &head
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+	right := *turtle
+	*turtle = nil
+	return head, right
+}
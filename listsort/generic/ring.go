@@ -0,0 +1,25 @@
+package generic
+
+// SortRing sorts a circular singly linked list with any of the
+// nil-terminated list sorts, breaking the ring just before start
+// and closing it again. It returns the ring's new start node,
+// the lowest node according to the sort.
+func SortRing[T any](start *Node[T], sortList func(*Node[T]) *Node[T]) *Node[T] {
+	if start == nil || start.Next == start {
+		return start
+	}
+
+	last := start
+	for last.Next != start {
+		last = last.Next
+	}
+	last.Next = nil
+
+	head := sortList(start)
+
+	for last = head; last.Next != nil; last = last.Next {
+	}
+	last.Next = head
+
+	return head
+}
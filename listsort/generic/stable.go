@@ -0,0 +1,30 @@
+package generic
+
+// Positions tags every node of an unsorted list with its
+// position in the list, for IsStable to check after sorting.
+func Positions[T any](head *Node[T]) map[*Node[T]]int {
+	positions := make(map[*Node[T]]int)
+	for node, i := head, 0; node != nil; node, i = node.Next, i+1 {
+		positions[node] = i
+	}
+	return positions
+}
+
+// IsStable walks a list sorted by cmp, returning the number of nodes
+// and whether every run of nodes cmp finds equal is still
+// in the order Positions recorded before sorting. If the sort
+// wasn't stable, the returned count is the position of the
+// first node out of its original order.
+func IsStable[T any](head *Node[T], positions map[*Node[T]]int, cmp func(a, b T) int) (int, bool) {
+	if head == nil {
+		return 0, true
+	}
+	sz := 1
+	for ; head.Next != nil; head = head.Next {
+		if cmp(head.Data, head.Next.Data) == 0 && positions[head] > positions[head.Next] {
+			return sz, false
+		}
+		sz++
+	}
+	return sz, true
+}
@@ -0,0 +1,80 @@
+package listsort
+
+// Mergesort is my own July 2021 iterative mergesort, using O(1) extra space.
+// Each pass over the list merges pairs of k-long sublists,
+// doubling k until a pass does only a single merge.
+func Mergesort(head *Node) *Node {
+	if head == nil {
+		return nil
+	}
+
+	var hd, tl *Node
+	appnd := func(n *Node) {
+		if hd == nil {
+			hd = n
+			tl = n
+			return
+		}
+		tl.Next = n
+		tl = n
+	}
+
+	p := head
+	mergecount := 2 // just to pass the first for-test
+
+	// The final pass over the unsorted linked list merges
+	// two lists each of about half the number of nodes.
+	// mergecount will have value 1 in that case. Don't
+	// need to loop again.
+	for k := 1; mergecount > 1; k *= 2 {
+
+		mergecount = 0
+
+		for p != nil {
+
+			psize := 0
+			q := p
+			for i := 0; q != nil && i < k; i++ {
+				psize++
+				q = q.Next
+			}
+
+			qsize := psize
+
+			for psize > 0 && qsize > 0 && q != nil {
+				if p.Data <= q.Data {
+					appnd(p)
+					p = p.Next
+					psize--
+					continue
+				}
+				appnd(q)
+				q = q.Next
+				qsize--
+			}
+
+			for ; psize > 0 && p != nil; psize-- {
+				appnd(p)
+				p = p.Next
+			}
+
+			for ; qsize > 0 && q != nil; qsize-- {
+				appnd(q)
+				q = q.Next
+			}
+
+			p = q
+
+			mergecount++
+		}
+
+		p = hd
+		head = hd
+
+		hd = nil
+		tl.Next = nil
+		tl = nil
+	}
+
+	return head
+}
@@ -0,0 +1,65 @@
+package listsort
+
+// ListSort is a port of the Linux kernel's list_sort,
+// lib/list_sort.c, the bottom up mergesort used by most of
+// the kernel's linked lists. Like BUMergesort it moves nodes
+// one at a time from the list to a set of pending sorted lists,
+// but it decides which pending lists to merge from the bits of
+// the count of nodes moved so far: it merges two pending lists
+// of size 2^k as soon as there's a third list of 2^k nodes
+// behind them. That keeps every merge balanced to at most 2:1,
+// even in the final merges, and costs no more comparisons
+// than merging 2^k lists of equal size.
+//
+// The kernel sorts doubly linked lists. It strings its pending
+// lists together through their first nodes' prev pointers,
+// and rebuilds all the prev pointers during the final merge.
+// Node has no prev pointer, so ListSort keeps its pending lists
+// in a small array, newest last.
+func ListSort(head *Node) *Node {
+	if head == nil {
+		return nil
+	}
+
+	// The kernel sorts lists of up to 2^64 nodes,
+	// which takes no more than 64 pending lists.
+	var pending [64]*Node
+	var npending int
+
+	list := head
+	for count := uint64(0); list != nil; count++ {
+		// Find the least significant clear bit in count.
+		// Every set bit below it is a pending list, newest first.
+		tail := npending - 1
+		bits := count
+		for ; bits&1 == 1; bits >>= 1 {
+			tail--
+		}
+
+		// If count isn't one less than a power of 2, merge
+		// the two pending lists at tail, older list first,
+		// and install the result in place of them.
+		if bits != 0 {
+			pending[tail-1] = Merge(pending[tail-1], pending[tail])
+			copy(pending[tail:npending-1], pending[tail+1:npending])
+			npending--
+			pending[npending] = nil
+		}
+
+		// Move one node from the list to pending.
+		next := list.Next
+		list.Next = nil
+		pending[npending] = list
+		npending++
+		list = next
+	}
+
+	// End of input, merge together all the pending lists,
+	// newest to oldest.
+	list = pending[npending-1]
+	for i := npending - 2; i >= 0; i-- {
+		list = Merge(pending[i], list)
+	}
+
+	return list
+}
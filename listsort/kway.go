@@ -0,0 +1,115 @@
+package listsort
+
+// kwayEntry is a min-heap element for KWayMerge. The index
+// of the list the node came from breaks ties between nodes
+// with equal data values, keeping the merge stable.
+type kwayEntry struct {
+	node *Node
+	list int
+}
+
+func (e kwayEntry) less(f kwayEntry) bool {
+	return e.node.Data < f.node.Data || (e.node.Data == f.node.Data && e.list < f.list)
+}
+
+// KWayMerge combines any number of sorted, nil-terminated lists into
+// a single sorted list. It keeps the head nodes of the lists in a
+// binary min-heap, so each node of the merged list costs O(log k)
+// comparisons, instead of the O(k) of merging each list in turn
+// into one growing list.
+// Nodes of lists earlier in the lists slice come before nodes
+// of later lists with equal data values. Any of the lists can be nil.
+func KWayMerge(lists []*Node) *Node {
+	heap := make([]kwayEntry, 0, len(lists))
+	for i, list := range lists {
+		if list != nil {
+			heap = append(heap, kwayEntry{node: list, list: i})
+		}
+	}
+	for i := len(heap)/2 - 1; i >= 0; i-- {
+		siftDown(heap, i)
+	}
+
+	var head *Node
+	tail := &head
+
+	for len(heap) > 1 {
+		top := heap[0].node
+		*tail = top
+		tail = &top.Next
+		if top.Next != nil {
+			heap[0].node = top.Next
+		} else {
+			heap[0] = heap[len(heap)-1]
+			heap = heap[:len(heap)-1]
+		}
+		siftDown(heap, 0)
+	}
+
+	if len(heap) == 1 {
+		// Only one list left, it's already sorted.
+		*tail = heap[0].node
+	}
+
+	return head
+}
+
+func siftDown(heap []kwayEntry, i int) {
+	for {
+		least := i
+		left := 2*i + 1
+		if left < len(heap) && heap[left].less(heap[least]) {
+			least = left
+		}
+		if right := left + 1; right < len(heap) && heap[right].less(heap[least]) {
+			least = right
+		}
+		if least == i {
+			return
+		}
+		heap[i], heap[least] = heap[least], heap[i]
+		i = least
+	}
+}
+
+// KWayMergeSort is a recursive mergesort that splits a list into k
+// sublists of nearly equal length instead of 2, sorts each sublist,
+// then combines them with KWayMerge. A k less than 2 sorts as if k was 2.
+func KWayMergeSort(head *Node, k int) *Node {
+	if k < 2 {
+		k = 2
+	}
+	return kwayMergeSort(head, ListSize(head), k)
+}
+
+func kwayMergeSort(head *Node, size int, k int) *Node {
+	if size < 2 {
+		return head
+	}
+
+	lists := make([]*Node, 0, k)
+
+	for i := 0; i < k; i++ {
+		// every sublist gets size/k nodes, the first size%k sublists
+		// get one more, so that all size nodes end up in a sublist.
+		sublistSize := size / k
+		if i < size%k {
+			sublistSize++
+		}
+		if sublistSize == 0 {
+			break
+		}
+
+		tail := head
+		for j := 1; j < sublistSize; j++ {
+			tail = tail.Next
+		}
+		next := tail.Next
+		tail.Next = nil
+
+		lists = append(lists, kwayMergeSort(head, sublistSize, k))
+		head = next
+	}
+
+	return KWayMerge(lists)
+}
@@ -0,0 +1,78 @@
+package listsort
+
+// NaturalMergesort takes advantage of order already in the list.
+// It detaches ascending runs of nodes from the list, then merges runs
+// the way BUMergesort merges 1-node lists. A presorted list is
+// a single run, and needs no merging at all.
+func NaturalMergesort(head *Node) *Node {
+	return naturalMergesort(head, false)
+}
+
+// NaturalMergesortReversing is NaturalMergesort, except that it also
+// finds strictly descending runs, and reverses them as it detaches them.
+// A reverse sorted list becomes a single run.
+func NaturalMergesortReversing(head *Node) *Node {
+	return naturalMergesort(head, true)
+}
+
+func naturalMergesort(head *Node, reverseRuns bool) *Node {
+	if head == nil {
+		return nil
+	}
+
+	var array [32]*Node
+	var result, next *Node
+	var i int
+
+	result = head
+
+	for result != nil {
+		result, next = nextRun(result, reverseRuns)
+
+		// array[i] holds runs from earlier in the list than result,
+		// so merging array[i] ahead of result keeps the sort stable.
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = Merge(array[i], result)
+			array[i] = nil
+		}
+		if i == 32 {
+			i--
+		}
+		array[i] = result
+		result = next
+	}
+
+	result = nil
+	for i = 0; i < 32; i++ {
+		result = Merge(array[i], result)
+	}
+
+	return result
+}
+
+// nextRun detaches the run of nodes at the beginning of a list,
+// returning the nil-terminated run and the rest of the list.
+// Descending runs have to be strictly descending, so that
+// reversing them doesn't reorder nodes with equal data values.
+func nextRun(head *Node, reverseRuns bool) (*Node, *Node) {
+	if reverseRuns && head.Next != nil && head.Next.Data < head.Data {
+		var run *Node
+		for p := head; ; {
+			next := p.Next
+			p.Next = run
+			run = p
+			if next == nil || next.Data >= p.Data {
+				return run, next
+			}
+			p = next
+		}
+	}
+
+	tail := head
+	for tail.Next != nil && tail.Data <= tail.Next.Data {
+		tail = tail.Next
+	}
+	next := tail.Next
+	tail.Next = nil
+	return head, next
+}
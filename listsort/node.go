@@ -0,0 +1,67 @@
+// Package listsort holds the linked list mergesort variants
+// that the benchmark programs time and compare:
+// the July 2021 iterative mergesort, a purely recursive mergesort,
+// a parallel recursive mergesort using goroutines,
+// a recursive mergesort with a user-level stack,
+// Wikipedia's bottom up mergesort with lists,
+// a port of the Linux kernel's list_sort,
+// and a natural mergesort that merges runs already in the list.
+// The iterative, recursive and bottom up sorts also have versions
+// for doubly linked lists.
+// All of them sort node data values low-to-high,
+// and all of them are stable: nodes with equal data values
+// keep their original order relative to each other.
+// Package listsort/generic has versions of the same
+// sorts for any data type, ordered by a comparison function.
+package listsort
+
+import (
+	"fmt"
+
+	"mergesort/listsort/generic"
+)
+
+// Node is an element of a linked list. It's the same type as
+// generic.Node[uint], so a list can be sorted by either the
+// hard-coded "<=" variants in this package, or the comparison
+// function variants in package generic.
+type Node = generic.Node[uint]
+
+// IsSorted walks a list, returning the number of nodes
+// and whether the list is sorted low-to-high. If the list
+// isn't sorted, the returned count is the position of the
+// first out-of-order node.
+func IsSorted(head *Node) (int, bool) {
+	if head == nil {
+		return 0, true
+	}
+	if head.Next == nil {
+		return 1, true
+	}
+	var sz int
+	for ; head.Next != nil; head = head.Next {
+		sz++
+		if head.Data > head.Next.Data {
+			return sz, false
+		}
+	}
+	sz++ // for-loop checks head.Next, count final element on list
+	return sz, true
+}
+
+// ListSize counts the nodes in a list
+func ListSize(node *Node) int {
+	count := 0
+	for ; node != nil; node = node.Next {
+		count++
+	}
+	return count
+}
+
+// Print runs a linked list and prints its values on stdout
+func Print(list *Node) {
+	for node := list; node != nil; node = node.Next {
+		fmt.Printf("%d -> ", node.Data)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,79 @@
+package observed
+
+// BUMergesort is listsort.BUMergesort, telling o what it does.
+func BUMergesort(head *Node, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{o}
+
+	// sizes[i] is the number of nodes in array[i]
+	var array [32]*Node
+	var sizes [32]int
+	var result, next *Node
+	var i, size int
+
+	result = head
+
+	for result != nil {
+		next = s.next(result)
+		s.setNext(result, nil)
+		size = 1
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = s.merge(array[i], sizes[i], result, size)
+			size += sizes[i]
+			array[i], sizes[i] = nil, 0
+		}
+		if i == 32 {
+			i--
+		}
+		array[i], sizes[i] = result, size
+		result = next
+	}
+
+	result, size = nil, 0
+	for i = 0; i < 32; i++ {
+		result = s.merge(array[i], sizes[i], result, size)
+		size += sizes[i]
+	}
+
+	return result
+}
+
+// merge is listsort.Merge of the pSize nodes of p
+// and the qSize nodes of q.
+func (s sorter) merge(p *Node, pSize int, q *Node, qSize int) *Node {
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+	s.o.Merge([]*Node{p, q}, []int{pSize, qSize})
+
+	x := &q
+	if s.lessEq(p, q) {
+		x = &p
+	}
+
+	h, t := *x, *x
+	*x = s.next(*x)
+
+	for p != nil && q != nil {
+		n := &q
+		if s.lessEq(p, q) {
+			n = &p
+		}
+		s.setNext(t, *n)
+		*n = s.next(*n)
+		t = s.next(t)
+	}
+
+	s.setNext(t, p)
+	if q != nil {
+		s.setNext(t, q)
+	}
+
+	return h
+}
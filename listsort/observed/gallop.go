@@ -0,0 +1,201 @@
+package observed
+
+import "mergesort/listsort"
+
+// gallopMerge is listsort.GallopMerge of the pSize nodes of p
+// and the qSize nodes of q.
+func (s sorter) gallopMerge(p *Node, pSize int, q *Node, qSize int, minGallop int) *Node {
+	if minGallop <= 0 {
+		return s.merge(p, pSize, q, qSize)
+	}
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+	s.o.Merge([]*Node{p, q}, []int{pSize, qSize})
+
+	// t is the last node of the merged list, nil while it's empty,
+	// standing for listsort.GallopMerge's pointer to h.
+	var h, t *Node
+	link := func(first, last *Node) {
+		if t == nil {
+			h = first
+		} else {
+			s.setNext(t, first)
+		}
+		t = last
+	}
+	var pWins, qWins int
+
+	for p != nil && q != nil {
+		if pWins >= minGallop {
+			pWins = 0
+			if count, last := s.gallop(p, q, false); count > 0 {
+				link(p, last)
+				p = s.next(last)
+				if p == nil {
+					break
+				}
+			}
+			// p's head sorts after q's head, no need to compare them.
+			link(q, q)
+			q = s.next(q)
+			qWins = 1
+			continue
+		}
+		if qWins >= minGallop {
+			qWins = 0
+			if count, last := s.gallop(q, p, true); count > 0 {
+				link(q, last)
+				q = s.next(last)
+				if q == nil {
+					break
+				}
+			}
+			// q's head sorts after p's head, no need to compare them.
+			link(p, p)
+			p = s.next(p)
+			pWins = 1
+			continue
+		}
+
+		if s.lessEq(p, q) {
+			link(p, p)
+			p = s.next(p)
+			pWins++
+			qWins = 0
+			continue
+		}
+		link(q, q)
+		q = s.next(q)
+		qWins++
+		pWins = 0
+	}
+
+	s.setNext(t, p)
+	if q != nil {
+		s.setNext(t, q)
+	}
+
+	return h
+}
+
+// gallop is listsort's gallop, comparing list's nodes
+// with the node value instead of with its data value.
+func (s sorter) gallop(list *Node, value *Node, strict bool) (int, *Node) {
+	ahead := func(node *Node) bool {
+		if strict {
+			return s.less(node, value)
+		}
+		return s.lessEq(node, value)
+	}
+
+	if !ahead(list) {
+		return 0, nil
+	}
+
+	// last is the furthest node known to sort ahead of value,
+	// lastOffset is its position in list.
+	last, lastOffset := list, 0
+
+	for step := 1; ; step *= 2 {
+		probe := last
+		i := 0
+		for ; i < step; i++ {
+			next := s.next(probe)
+			if next == nil {
+				break
+			}
+			probe = next
+		}
+		if i == 0 {
+			// every node of list sorts ahead of value
+			return lastOffset + 1, last
+		}
+		if ahead(probe) {
+			last, lastOffset = probe, lastOffset+i
+			continue
+		}
+
+		// Binary search the nodes between last, which sorts ahead
+		// of value, and probe, i nodes further on, which doesn't.
+		lo, hi := 0, i
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			node := last
+			for j := lo; j < mid; j++ {
+				node = s.next(node)
+			}
+			if ahead(node) {
+				last, lo = node, mid
+			} else {
+				hi = mid
+			}
+		}
+		return lastOffset + lo + 1, last
+	}
+}
+
+// RecursiveMergeSortGalloping is listsort.RecursiveMergeSortGalloping,
+// telling o what it does.
+func RecursiveMergeSortGalloping(head *Node, minGallop int, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{o}
+	return s.recursiveMergeSortGalloping(head, listsort.ListSize(head), minGallop)
+}
+
+func (s sorter) recursiveMergeSortGalloping(head *Node, size int, minGallop int) *Node {
+	if s.next(head) == nil {
+		return head
+	}
+	left, right := s.split(head, size)
+	left = s.recursiveMergeSortGalloping(left, size/2, minGallop)
+	right = s.recursiveMergeSortGalloping(right, size-size/2, minGallop)
+	return s.gallopMerge(left, size/2, right, size-size/2, minGallop)
+}
+
+// BUMergesortGalloping is listsort.BUMergesortGalloping,
+// telling o what it does.
+func BUMergesortGalloping(head *Node, minGallop int, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{o}
+
+	// sizes[i] is the number of nodes in array[i]
+	var array [32]*Node
+	var sizes [32]int
+	var result, next *Node
+	var i, size int
+
+	result = head
+
+	for result != nil {
+		next = s.next(result)
+		s.setNext(result, nil)
+		size = 1
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = s.gallopMerge(array[i], sizes[i], result, size, minGallop)
+			size += sizes[i]
+			array[i], sizes[i] = nil, 0
+		}
+		if i == 32 {
+			i--
+		}
+		array[i], sizes[i] = result, size
+		result = next
+	}
+
+	result, size = nil, 0
+	for i = 0; i < 32; i++ {
+		result = s.gallopMerge(array[i], sizes[i], result, size, minGallop)
+		size += sizes[i]
+	}
+
+	return result
+}
@@ -0,0 +1,89 @@
+package observed
+
+import "mergesort/listsort"
+
+// Mergesort is listsort.Mergesort, the July 2021 iterative
+// mergesort, telling o what it does. Walking the first k-long
+// list of a pair to find the second list counts as steps,
+// but not as a Split.
+func Mergesort(head *Node, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{o}
+	size := listsort.ListSize(head)
+
+	var hd, tl *Node
+	appnd := func(n *Node) {
+		if hd == nil {
+			hd = n
+			tl = n
+			return
+		}
+		s.setNext(tl, n)
+		tl = n
+	}
+
+	p := head
+	mergecount := 2 // just to pass the first for-test
+
+	for k := 1; mergecount > 1; k *= 2 {
+
+		mergecount = 0
+		// remaining counts the nodes from p to the end of the list
+		remaining := size
+
+		for p != nil {
+
+			psize := 0
+			q := p
+			for i := 0; q != nil && i < k; i++ {
+				psize++
+				q = s.step(q)
+			}
+
+			qsize := psize
+
+			qLength := min(qsize, remaining-psize)
+			remaining -= psize + qLength
+			if q != nil {
+				s.o.Merge([]*Node{p, q}, []int{psize, qLength})
+			}
+
+			for psize > 0 && qsize > 0 && q != nil {
+				if s.lessEq(p, q) {
+					appnd(p)
+					p = s.next(p)
+					psize--
+					continue
+				}
+				appnd(q)
+				q = s.next(q)
+				qsize--
+			}
+
+			for ; psize > 0 && p != nil; psize-- {
+				appnd(p)
+				p = s.next(p)
+			}
+
+			for ; qsize > 0 && q != nil; qsize-- {
+				appnd(q)
+				q = s.next(q)
+			}
+
+			p = q
+
+			mergecount++
+		}
+
+		p = hd
+		head = hd
+
+		hd = nil
+		s.setNext(tl, nil)
+		tl = nil
+	}
+
+	return head
+}
@@ -0,0 +1,56 @@
+package observed
+
+// ListSort is listsort.ListSort, the Linux kernel's list_sort,
+// telling o what it does.
+func ListSort(head *Node, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{o}
+
+	// sizes[i] is the number of nodes in pending[i]
+	var pending [64]*Node
+	var sizes [64]int
+	var npending int
+
+	list := head
+	for count := uint64(0); list != nil; count++ {
+		// Find the least significant clear bit in count.
+		// Every set bit below it is a pending list, newest first.
+		tail := npending - 1
+		bits := count
+		for ; bits&1 == 1; bits >>= 1 {
+			tail--
+		}
+
+		// If count isn't one less than a power of 2, merge
+		// the two pending lists at tail, older list first,
+		// and install the result in place of them.
+		if bits != 0 {
+			pending[tail-1] = s.merge(pending[tail-1], sizes[tail-1], pending[tail], sizes[tail])
+			sizes[tail-1] += sizes[tail]
+			copy(pending[tail:npending-1], pending[tail+1:npending])
+			copy(sizes[tail:npending-1], sizes[tail+1:npending])
+			npending--
+			pending[npending], sizes[npending] = nil, 0
+		}
+
+		// Move one node from the list to pending.
+		next := s.next(list)
+		s.setNext(list, nil)
+		pending[npending], sizes[npending] = list, 1
+		npending++
+		list = next
+	}
+
+	// End of input, merge together all the pending lists,
+	// newest to oldest.
+	list = pending[npending-1]
+	size := sizes[npending-1]
+	for i := npending - 2; i >= 0; i-- {
+		list = s.merge(pending[i], sizes[i], list, size)
+		size += sizes[i]
+	}
+
+	return list
+}
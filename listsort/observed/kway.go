@@ -0,0 +1,125 @@
+package observed
+
+import "mergesort/listsort"
+
+// kwayEntry is listsort's kwayEntry, a min-heap element for kwayMerge.
+type kwayEntry struct {
+	node *Node
+	list int
+}
+
+func (s sorter) kwayLess(e, f kwayEntry) bool {
+	s.o.Read(e.node)
+	s.o.Read(f.node)
+	s.o.Compare(e.node, f.node)
+	return e.node.Data < f.node.Data || (e.node.Data == f.node.Data && e.list < f.list)
+}
+
+// kwayMerge is listsort.KWayMerge of lists, lists[i] holding sizes[i] nodes.
+func (s sorter) kwayMerge(lists []*Node, sizes []int) *Node {
+	s.o.Merge(lists, sizes)
+
+	heap := make([]kwayEntry, 0, len(lists))
+	for i, list := range lists {
+		if list != nil {
+			heap = append(heap, kwayEntry{node: list, list: i})
+		}
+	}
+	for i := len(heap)/2 - 1; i >= 0; i-- {
+		s.siftDown(heap, i)
+	}
+
+	// tail is the last node of the merged list, nil while it's empty,
+	// standing for listsort.KWayMerge's pointer to head.
+	var head, tail *Node
+
+	for len(heap) > 1 {
+		top := heap[0].node
+		if tail == nil {
+			head = top
+		} else {
+			s.setNext(tail, top)
+		}
+		tail = top
+		if next := s.next(top); next != nil {
+			heap[0].node = next
+		} else {
+			heap[0] = heap[len(heap)-1]
+			heap = heap[:len(heap)-1]
+		}
+		s.siftDown(heap, 0)
+	}
+
+	if len(heap) == 1 {
+		// Only one list left, it's already sorted.
+		if tail == nil {
+			head = heap[0].node
+		} else {
+			s.setNext(tail, heap[0].node)
+		}
+	}
+
+	return head
+}
+
+func (s sorter) siftDown(heap []kwayEntry, i int) {
+	for {
+		least := i
+		left := 2*i + 1
+		if left < len(heap) && s.kwayLess(heap[left], heap[least]) {
+			least = left
+		}
+		if right := left + 1; right < len(heap) && s.kwayLess(heap[right], heap[least]) {
+			least = right
+		}
+		if least == i {
+			return
+		}
+		heap[i], heap[least] = heap[least], heap[i]
+		i = least
+	}
+}
+
+// KWayMergeSort is listsort.KWayMergeSort, telling o what it does.
+func KWayMergeSort(head *Node, k int, o Observer) *Node {
+	if k < 2 {
+		k = 2
+	}
+	s := sorter{o}
+	return s.kwayMergeSort(head, listsort.ListSize(head), k)
+}
+
+func (s sorter) kwayMergeSort(head *Node, size int, k int) *Node {
+	if size < 2 {
+		return head
+	}
+	s.o.Split(head, size)
+
+	lists := make([]*Node, 0, k)
+	sizes := make([]int, 0, k)
+
+	for i := 0; i < k; i++ {
+		// every sublist gets size/k nodes, the first size%k sublists
+		// get one more, so that all size nodes end up in a sublist.
+		sublistSize := size / k
+		if i < size%k {
+			sublistSize++
+		}
+		if sublistSize == 0 {
+			break
+		}
+
+		tail := head
+		for j := 1; j < sublistSize; j++ {
+			tail = s.step(tail)
+		}
+		next := s.next(tail)
+		s.setNext(tail, nil)
+
+		lists = append(lists, s.kwayMergeSort(head, sublistSize, k))
+		sizes = append(sizes, sublistSize)
+		head = next
+	}
+
+	return s.kwayMerge(lists, sizes)
+}
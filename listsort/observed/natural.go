@@ -0,0 +1,79 @@
+package observed
+
+// NaturalMergesort is listsort.NaturalMergesort, telling o
+// what it does. Walking along a run to find its end counts
+// as steps, but not as a Split.
+func NaturalMergesort(head *Node, o Observer) *Node {
+	return sorter{o}.naturalMergesort(head, false)
+}
+
+// NaturalMergesortReversing is listsort.NaturalMergesortReversing,
+// telling o what it does.
+func NaturalMergesortReversing(head *Node, o Observer) *Node {
+	return sorter{o}.naturalMergesort(head, true)
+}
+
+func (s sorter) naturalMergesort(head *Node, reverseRuns bool) *Node {
+	if head == nil {
+		return nil
+	}
+
+	// sizes[i] is the number of nodes in array[i]
+	var array [32]*Node
+	var sizes [32]int
+	var result, next *Node
+	var i, size int
+
+	result = head
+
+	for result != nil {
+		result, next, size = s.nextRun(result, reverseRuns)
+
+		for i = 0; (i < 32) && (array[i] != nil); i++ {
+			result = s.merge(array[i], sizes[i], result, size)
+			size += sizes[i]
+			array[i], sizes[i] = nil, 0
+		}
+		if i == 32 {
+			i--
+		}
+		array[i], sizes[i] = result, size
+		result = next
+	}
+
+	result, size = nil, 0
+	for i = 0; i < 32; i++ {
+		result = s.merge(array[i], sizes[i], result, size)
+		size += sizes[i]
+	}
+
+	return result
+}
+
+// nextRun is listsort's nextRun, also returning the run's length.
+func (s sorter) nextRun(head *Node, reverseRuns bool) (*Node, *Node, int) {
+	if reverseRuns && s.next(head) != nil && s.less(head.Next, head) {
+		var run *Node
+		size := 0
+		for p := head; ; {
+			next := s.step(p)
+			s.setNext(p, run)
+			run = p
+			size++
+			if next == nil || !s.less(next, p) {
+				return run, next, size
+			}
+			p = next
+		}
+	}
+
+	tail := head
+	size := 1
+	for s.next(tail) != nil && s.lessEq(tail, tail.Next) {
+		tail = s.step(tail)
+		size++
+	}
+	next := s.next(tail)
+	s.setNext(tail, nil)
+	return head, next, size
+}
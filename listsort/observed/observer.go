@@ -0,0 +1,212 @@
+// Package observed holds versions of the listsort mergesorts that
+// tell an Observer about every node they read or write, every
+// comparison, every step of walking a list to split it, and every
+// split and merge, so that programs can count, simulate or trace
+// the work of any of the sorts without a copy of their own.
+// The listsort sorts stay as they are, without any calls to an
+// observer, so that the sorts mergetest times are as fast as they
+// can be.
+//
+// The reads and writes are the loads and stores of .Data and .Next
+// fields written in the listsort source code. The compiler may keep
+// some of them in registers instead. Sorts that need the length of
+// a list to report the sizes of splits and merges count its nodes
+// before sorting, without telling the observer.
+package observed
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"mergesort/listsort"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+// Observer gets told about what an observed sort does to list nodes,
+// as the sort does it.
+type Observer interface {
+	// Read is a load of node's .Data or .Next field.
+	Read(node *Node)
+	// Write is a store to node's .Next field.
+	Write(node *Node)
+	// Compare is a comparison of a's data value with b's,
+	// after the Reads of both.
+	Compare(a, b *Node)
+	// Step is moving along a list from node, to find where to split
+	// the list or where a run ends, after the Read of node's .Next.
+	Step(node *Node)
+	// Split comes before walking the size nodes starting at head,
+	// to split them into 2 lists, or k lists for the k-way mergesort.
+	Split(head *Node, size int)
+	// Merge comes before merging sorted, nil-terminated lists,
+	// lists[i] holding sizes[i] nodes. Nodes of earlier lists come
+	// before nodes of later lists with equal data values.
+	Merge(lists []*Node, sizes []int)
+}
+
+// NopObserver ignores everything. Embedding it in an Observer
+// leaves only the methods for the events it cares about to write.
+type NopObserver struct{}
+
+func (NopObserver) Read(*Node)           {}
+func (NopObserver) Write(*Node)          {}
+func (NopObserver) Compare(*Node, *Node) {}
+func (NopObserver) Step(*Node)           {}
+func (NopObserver) Split(*Node, int)     {}
+func (NopObserver) Merge([]*Node, []int) {}
+
+// lockedObserver passes events on to an Observer one at a time,
+// for ParallelMergeSort's goroutines.
+type lockedObserver struct {
+	mu sync.Mutex
+	o  Observer
+}
+
+func (l *lockedObserver) Read(node *Node) {
+	l.mu.Lock()
+	l.o.Read(node)
+	l.mu.Unlock()
+}
+
+func (l *lockedObserver) Write(node *Node) {
+	l.mu.Lock()
+	l.o.Write(node)
+	l.mu.Unlock()
+}
+
+func (l *lockedObserver) Compare(a, b *Node) {
+	l.mu.Lock()
+	l.o.Compare(a, b)
+	l.mu.Unlock()
+}
+
+func (l *lockedObserver) Step(node *Node) {
+	l.mu.Lock()
+	l.o.Step(node)
+	l.mu.Unlock()
+}
+
+func (l *lockedObserver) Split(head *Node, size int) {
+	l.mu.Lock()
+	l.o.Split(head, size)
+	l.mu.Unlock()
+}
+
+func (l *lockedObserver) Merge(lists []*Node, sizes []int) {
+	l.mu.Lock()
+	l.o.Merge(lists, sizes)
+	l.mu.Unlock()
+}
+
+// sorter holds the Observer of a sort in progress, and
+// does the node accesses the sorts report.
+type sorter struct {
+	o Observer
+}
+
+// next loads node.Next
+func (s sorter) next(node *Node) *Node {
+	s.o.Read(node)
+	return node.Next
+}
+
+// step loads node.Next, moving along a list to find where to split it
+func (s sorter) step(node *Node) *Node {
+	s.o.Read(node)
+	s.o.Step(node)
+	return node.Next
+}
+
+// setNext stores next in node.Next
+func (s sorter) setNext(node, next *Node) {
+	s.o.Write(node)
+	node.Next = next
+}
+
+// lessEq reports whether a's data value is less than or equal to b's
+func (s sorter) lessEq(a, b *Node) bool {
+	s.o.Read(a)
+	s.o.Read(b)
+	s.o.Compare(a, b)
+	return a.Data <= b.Data
+}
+
+// less reports whether a's data value is less than b's
+func (s sorter) less(a, b *Node) bool {
+	s.o.Read(a)
+	s.o.Read(b)
+	s.o.Compare(a, b)
+	return a.Data < b.Data
+}
+
+// Names lists the algorithms Lookup knows, in the order
+// programs that report on all of them use.
+var Names = []string{
+	"recursive",
+	"ownstack",
+	"parallel",
+	"bottomup",
+	"iterative",
+	"natural",
+	"naturalreversing",
+	"listsort",
+	"recursivegallop",
+	"bottomupgallop",
+	"kway",
+}
+
+// Options are the parameters of the algorithms that take one.
+type Options struct {
+	MinGallop int // galloping threshold of recursivegallop and bottomupgallop
+	Depth     int // goroutine depth of parallel
+	K         int // number of sublists of kway
+}
+
+// Lookup returns the observed sort called name, one of Names,
+// with its parameters from opts.
+func Lookup(name string, opts Options) (func(head *Node, o Observer) *Node, error) {
+	switch name {
+	case "recursive":
+		return RecursiveMergeSort, nil
+	case "ownstack":
+		return OwnstackMergeSort, nil
+	case "parallel":
+		return func(head *Node, o Observer) *Node { return ParallelMergeSort(head, opts.Depth, o) }, nil
+	case "bottomup":
+		return BUMergesort, nil
+	case "iterative":
+		return Mergesort, nil
+	case "natural":
+		return NaturalMergesort, nil
+	case "naturalreversing":
+		return NaturalMergesortReversing, nil
+	case "listsort":
+		return ListSort, nil
+	case "recursivegallop":
+		return func(head *Node, o Observer) *Node { return RecursiveMergeSortGalloping(head, opts.MinGallop, o) }, nil
+	case "bottomupgallop":
+		return func(head *Node, o Observer) *Node { return BUMergesortGalloping(head, opts.MinGallop, o) }, nil
+	case "kway":
+		return func(head *Node, o Observer) *Node { return KWayMergeSort(head, opts.K, o) }, nil
+	}
+	return nil, fmt.Errorf("unknown algorithm %q, want one of %v", name, Names)
+}
+
+// Select returns the algorithms named in spec, a comma separated
+// list of Names, or all of Names if spec is "all".
+func Select(spec string) ([]string, error) {
+	if spec == "all" {
+		return Names, nil
+	}
+	names := strings.Split(spec, ",")
+	for _, name := range names {
+		if !slices.Contains(Names, name) {
+			return nil, fmt.Errorf("unknown algorithm %q, want one of %v", name, Names)
+		}
+	}
+	return names, nil
+}
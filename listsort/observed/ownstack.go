@@ -0,0 +1,80 @@
+package observed
+
+import "mergesort/listsort"
+
+type stackFrame struct {
+	list   *Node
+	merged *Node
+	size   int // nodes in list or merged
+	next   *stackFrame
+}
+
+// OwnstackMergeSort is listsort.OwnstackMergeSort, telling o
+// what it does.
+func OwnstackMergeSort(head *Node, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{o}
+
+	stack := &stackFrame{
+		list: head,
+		size: listsort.ListSize(head),
+	}
+
+	var sorted *Node
+
+	for {
+		var elem *stackFrame
+
+		elem, stack = stack, stack.next
+
+		if elem.list == nil && stack == nil {
+			sorted = elem.merged
+			break
+		}
+
+		if elem.list != nil && s.next(elem.list) == nil {
+			// "recursion" has bottomed out at 1-node list
+			elem.merged, elem.list = elem.list, nil
+			elem.next, stack = stack, elem
+			continue
+		}
+
+		if elem.merged != nil {
+			// a merged sublist has "returned"
+
+			tmp := stack
+			stack = stack.next
+
+			if tmp.merged == nil {
+				elem.next, stack = stack, elem
+				tmp.next, stack = stack, tmp
+				continue
+			}
+
+			// both tmp and elem contain merged sublists
+
+			elem.merged = s.merge(elem.merged, elem.size, tmp.merged, tmp.size)
+			elem.size += tmp.size
+			elem.next, stack = stack, elem
+			// discarding tmp
+			continue
+		}
+
+		// still "recursing"
+		left, right := s.split(elem.list, elem.size)
+		stack = &stackFrame{
+			list: left,
+			size: elem.size / 2,
+			next: stack,
+		}
+		stack = &stackFrame{
+			list: right,
+			size: elem.size - elem.size/2,
+			next: stack,
+		}
+	}
+
+	return sorted
+}
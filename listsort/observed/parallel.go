@@ -0,0 +1,32 @@
+package observed
+
+import "mergesort/listsort"
+
+// ParallelMergeSort is listsort.ParallelMergeSort, telling o
+// what it does. The goroutines tell o one event at a time,
+// but in whatever order they happen to run.
+func ParallelMergeSort(head *Node, depth int, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{&lockedObserver{o: o}}
+	return s.parallelMergeSort(head, listsort.ListSize(head), depth)
+}
+
+func (s sorter) parallelMergeSort(head *Node, size int, depth int) *Node {
+	if depth <= 0 || s.next(head) == nil {
+		return s.recursiveMergeSort(head, size)
+	}
+
+	left, right := s.split(head, size)
+
+	sortedRight := make(chan *Node)
+	go func() {
+		sortedRight <- s.parallelMergeSort(right, size-size/2, depth-1)
+	}()
+	left = s.parallelMergeSort(left, size/2, depth-1)
+	right = <-sortedRight
+
+	// left holds the earlier nodes of the list, so merge it first.
+	return s.merge(left, size/2, right, size-size/2)
+}
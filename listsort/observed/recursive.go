@@ -0,0 +1,54 @@
+package observed
+
+import "mergesort/listsort"
+
+// RecursiveMergeSort is listsort.RecursiveMergeSort, telling o
+// what it does.
+func RecursiveMergeSort(head *Node, o Observer) *Node {
+	if head == nil {
+		return nil
+	}
+	s := sorter{o}
+	return s.recursiveMergeSort(head, listsort.ListSize(head))
+}
+
+func (s sorter) recursiveMergeSort(head *Node, size int) *Node {
+	if s.next(head) == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	left, right := s.split(head, size)
+
+	left = s.recursiveMergeSort(left, size/2)
+	right = s.recursiveMergeSort(right, size-size/2)
+
+	return s.merge(left, size/2, right, size-size/2)
+}
+
+// split is listsort.Split of a list of size nodes, at least 2.
+// The left list gets size/2 nodes, the right list the rest.
+func (s sorter) split(head *Node, size int) (*Node, *Node) {
+	s.o.Split(head, size)
+
+	// turtle ends up the last node of the left list.
+	// It starts out nil, standing for the head variable
+	// that listsort.Split's turtle points to.
+	var turtle *Node
+	rabbit := s.step(head)
+
+	for rabbit != nil {
+		if turtle == nil {
+			turtle = head
+		} else {
+			turtle = s.step(turtle)
+		}
+		if rabbit = s.step(rabbit); rabbit != nil {
+			rabbit = s.step(rabbit)
+		}
+	}
+
+	right := s.next(turtle)
+	s.setNext(turtle, nil)
+	return head, right
+}
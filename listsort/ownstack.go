@@ -0,0 +1,73 @@
+package listsort
+
+type stackFrame struct {
+	list   *Node
+	merged *Node
+	next   *stackFrame
+}
+
+// OwnstackMergeSort is a recursive mergesort that keeps
+// its own stack of heap-allocated frames instead of
+// using the function call stack.
+func OwnstackMergeSort(head *Node) *Node {
+	if head == nil {
+		return nil
+	}
+
+	stack := &stackFrame{
+		list: head,
+	}
+
+	var sorted *Node
+
+	for {
+		var elem *stackFrame
+
+		elem, stack = stack, stack.next
+
+		if elem.list == nil && stack == nil {
+			sorted = elem.merged
+			break
+		}
+
+		if elem.list != nil && elem.list.Next == nil {
+			// "recursion" has bottomed out at 1-node list
+			elem.merged, elem.list = elem.list, nil
+			elem.next, stack = stack, elem
+			continue
+		}
+
+		if elem.merged != nil {
+			// a merged sublist has "returned"
+
+			tmp := stack
+			stack = stack.next
+
+			if tmp.merged == nil {
+				elem.next, stack = stack, elem
+				tmp.next, stack = stack, tmp
+				continue
+			}
+
+			// both tmp and elem contain merged sublists
+
+			elem.merged = Merge(elem.merged, tmp.merged)
+			elem.next, stack = stack, elem
+			// discarding tmp
+			continue
+		}
+
+		// still "recursing"
+		left, right := Split(elem.list)
+		stack = &stackFrame{
+			list: left,
+			next: stack,
+		}
+		stack = &stackFrame{
+			list: right,
+			next: stack,
+		}
+	}
+
+	return sorted
+}
@@ -0,0 +1,24 @@
+package listsort
+
+// ParallelMergeSort is RecursiveMergeSort with the two halves of the list
+// sorted in separate goroutines, down to depth levels of recursion.
+// Below depth levels, it falls back to sequential recursion.
+// Depth d can keep up to 2^d goroutines busy, and a depth of 0
+// is the same as RecursiveMergeSort.
+func ParallelMergeSort(head *Node, depth int) *Node {
+	if depth <= 0 || head == nil || head.Next == nil {
+		return RecursiveMergeSort(head)
+	}
+
+	left, right := Split(head)
+
+	sortedRight := make(chan *Node)
+	go func() {
+		sortedRight <- ParallelMergeSort(right, depth-1)
+	}()
+	left = ParallelMergeSort(left, depth-1)
+	right = <-sortedRight
+
+	// left holds the earlier nodes of the list, so merge it first.
+	return Merge(left, right)
+}
@@ -0,0 +1,86 @@
+package listsort
+
+// RecursiveMergeSort is a purely recursive mergesort. It splits
+// the list in half by walking it with a rabbit and turtle,
+// sorts each half, then merges the sorted halves in-line.
+func RecursiveMergeSort(head *Node) *Node {
+	if head == nil || head.Next == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	// Setting rabbit and turtle like this means we split an
+	// odd-length-list (head) into lists of length n (right)
+	// and n+1 (left).
+	rabbit, turtle := head.Next, &head
+
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+
+	right := *turtle
+	*turtle = nil
+
+	left := RecursiveMergeSort(head)
+	right = RecursiveMergeSort(right)
+
+	// Set h, t variables so that the loop doing the merge
+	// does not have to have a "if h == nil" check every iteration.
+	x := &right
+	if left.Data <= right.Data {
+		x = &left
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	// left and right are either equal in length, or right is one
+	// node longer, but the "<=" check might take more from one list
+	// than the other. Have to check both for nil.
+	for left != nil && right != nil {
+		n := &right
+		if left.Data <= right.Data {
+			n = &left
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+		// At the end of this for-loop, t.Next ends up being nil
+		// because of the left/right list splitting.
+	}
+
+	// Either left or right are nil. If left == nil,
+	// assigning nil to t.Next is no issue.
+	t.Next = left
+	if right != nil {
+		// but if right is nil, can't assign nil to t.Next,
+		// because left was non-nil.
+		t.Next = right
+	}
+
+	return h
+}
+
+// Split divides a list of at least 2 nodes into two nil-terminated
+// lists, using the same rabbit and turtle walk as RecursiveMergeSort.
+func Split(head *Node) (*Node, *Node) {
+	// Setting rabbit and turtle like this means we split an
+	// odd-length-list (head) into lists of length n (right)
+	// and n+1 (left).
+	rabbit, turtle := head.Next, &head
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+	right := *turtle
+	*turtle = nil
+	return head, right
+}
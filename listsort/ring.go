@@ -0,0 +1,63 @@
+package listsort
+
+// SortRing sorts a circular singly linked list with any of the
+// nil-terminated list sorts. It breaks the ring just before start,
+// sorts the resulting list, then closes the ring again.
+// It returns the ring's new start node, the node with the lowest
+// data value. A nil start is an empty ring.
+func SortRing(start *Node, sortList func(*Node) *Node) *Node {
+	if start == nil || start.Next == start {
+		return start
+	}
+
+	// find the node before start, and break the ring there
+	last := start
+	for last.Next != start {
+		last = last.Next
+	}
+	last.Next = nil
+
+	head := sortList(start)
+
+	for last = head; last.Next != nil; last = last.Next {
+	}
+	last.Next = head
+
+	return head
+}
+
+// IsSortedRing walks a circular list from start all the way around,
+// returning the number of nodes and whether the data values are
+// sorted low-to-high going from start back to start.
+// It doesn't loop forever on a list that isn't a ring:
+// a nil .Next pointer, or a loop back to some node other than start,
+// makes it return false. If the ring isn't sorted, the returned
+// count is the position of the first out-of-order node.
+func IsSortedRing(start *Node) (int, bool) {
+	if start == nil {
+		return 0, true
+	}
+
+	// The turtle moves one node for every two the walk does,
+	// so the walk catches it if the list loops back without
+	// passing through start.
+	turtle := start
+	sz := 1
+	for node := start; node.Next != start; node = node.Next {
+		if node.Next == nil {
+			return sz, false
+		}
+		if node.Data > node.Next.Data {
+			return sz, false
+		}
+		if sz%2 == 0 {
+			turtle = turtle.Next
+		}
+		sz++
+		if node.Next == turtle && turtle != start {
+			return sz, false
+		}
+	}
+
+	return sz, true
+}
@@ -0,0 +1,30 @@
+package listsort
+
+// Positions tags every node of an unsorted list with its
+// position in the list, for IsStable to check after sorting.
+func Positions(head *Node) map[*Node]int {
+	positions := make(map[*Node]int)
+	for node, i := head, 0; node != nil; node, i = node.Next, i+1 {
+		positions[node] = i
+	}
+	return positions
+}
+
+// IsStable walks a sorted list, returning the number of nodes
+// and whether every run of nodes with equal data values is still
+// in the order Positions recorded before sorting. If the sort
+// wasn't stable, the returned count is the position of the
+// first node out of its original order.
+func IsStable(head *Node, positions map[*Node]int) (int, bool) {
+	if head == nil {
+		return 0, true
+	}
+	sz := 1
+	for ; head.Next != nil; head = head.Next {
+		if head.Data == head.Next.Data && positions[head] > positions[head.Next] {
+			return sz, false
+		}
+		sz++
+	}
+	return sz, true
+}
@@ -0,0 +1,175 @@
+// Package memtrace records the list node reads and writes a mergesort
+// does to a compact binary trace file, and reads trace files back,
+// so that the memory accesses of a sort can be analysed offline.
+// A Tracer hears about the reads and writes from any of the
+// listsort/observed mergesorts.
+//
+// A trace file starts with an 8-byte header: the 4 bytes "MTRC",
+// a version byte, currently 1, a zero byte, and the node size in
+// bytes as a little-endian uint16. Each record after the header is
+// a byte holding the merge level shifted left 1 bit, or'ed with
+// the operation, Read (0) or Write (1), then the difference between
+// the record's node address and the previous record's node address,
+// the first record's from 0, as an encoding/binary varint.
+// Most differences take 2 or 3 bytes.
+package memtrace
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Op is the kind of a node access
+type Op uint8
+
+const (
+	Read Op = iota
+	Write
+)
+
+func (op Op) String() string {
+	if op == Write {
+		return "write"
+	}
+	return "read"
+}
+
+// MaxLevel is the biggest merge level a record can hold.
+const MaxLevel = 127
+
+// MaxNodeSize is the biggest node size a trace header can hold.
+const MaxNodeSize = math.MaxUint16
+
+const (
+	magic   = "MTRC"
+	version = 1
+)
+
+// Record is one node access: the operation, the address of the node,
+// and the merge level, the number of times the nodes of the list
+// being split or merged could have been merged already, rounded up:
+// 1 for lists of 2 nodes, 2 for lists of 3 or 4, 10 for 1000.
+// Level 0 is accesses before the sort's first split or merge.
+type Record struct {
+	Op    Op
+	Level int
+	Addr  uintptr
+}
+
+// Level is the merge level of splitting or merging a list of n nodes.
+func Level(n int) int {
+	level := 0
+	for size := 1; size < n; size <<= 1 {
+		level++
+	}
+	return level
+}
+
+// Writer writes records to a trace file. Records counts
+// the records written. Errors are saved until Flush,
+// so a sort being traced doesn't have to check them.
+type Writer struct {
+	Records int64
+
+	w    *bufio.Writer
+	last uintptr
+	buf  [1 + binary.MaxVarintLen64]byte
+	err  error
+}
+
+// NewWriter writes the header of a trace of nodes
+// nodeSize bytes in size to w. The header holds the node size
+// in 16 bits, so nodeSize can't be more than MaxNodeSize.
+func NewWriter(w io.Writer, nodeSize int) (*Writer, error) {
+	if nodeSize < 1 || nodeSize > MaxNodeSize {
+		return nil, fmt.Errorf("can't trace %d-byte nodes, node size has to be 1 to %d bytes", nodeSize, MaxNodeSize)
+	}
+	tw := &Writer{w: bufio.NewWriter(w)}
+	var header [8]byte
+	copy(header[:], magic)
+	header[4] = version
+	binary.LittleEndian.PutUint16(header[6:], uint16(nodeSize))
+	if _, err := tw.w.Write(header[:]); err != nil {
+		return nil, err
+	}
+	return tw, nil
+}
+
+// Record writes a record of an op access at level to
+// the node at addr. Levels over MaxLevel are written as MaxLevel.
+func (w *Writer) Record(op Op, level int, addr uintptr) {
+	if w.err != nil {
+		return
+	}
+	w.buf[0] = byte(min(level, MaxLevel))<<1 | byte(op)
+	n := binary.PutVarint(w.buf[1:], int64(addr-w.last))
+	w.last = addr
+	_, w.err = w.w.Write(w.buf[:1+n])
+	w.Records++
+}
+
+// Flush writes any buffered records, and returns the first error
+// writing any record.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// Reader reads records from a trace file. NodeSize is the size
+// in bytes of the traced nodes, from the header.
+type Reader struct {
+	NodeSize int
+
+	r    *bufio.Reader
+	last uintptr
+}
+
+// ErrNotTrace means a file doesn't start with a trace header.
+var ErrNotTrace = errors.New("not a memory access trace")
+
+// NewReader reads the header of the trace in r.
+func NewReader(r io.Reader) (*Reader, error) {
+	tr := &Reader{r: bufio.NewReader(r)}
+	var header [8]byte
+	if _, err := io.ReadFull(tr.r, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrNotTrace
+		}
+		return nil, err
+	}
+	if string(header[:4]) != magic {
+		return nil, ErrNotTrace
+	}
+	if header[4] != version {
+		return nil, fmt.Errorf("memory access trace version %d, only know version %d", header[4], version)
+	}
+	tr.NodeSize = int(binary.LittleEndian.Uint16(header[6:]))
+	return tr, nil
+}
+
+// Next returns the next record, or io.EOF after the last one.
+func (r *Reader) Next() (Record, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return Record{}, err
+	}
+	delta, err := binary.ReadVarint(r.r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+	r.last += uintptr(delta)
+	return Record{
+		Op:    Op(b & 1),
+		Level: int(b >> 1),
+		Addr:  r.last,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package memtrace
+
+// Reuse finds reuse distances: for each access to an address, the
+// number of distinct addresses accessed since the last access to it.
+// An LRU cache of c lines, fully associative, hits on exactly
+// the accesses whose reuse distance in lines is less than c.
+//
+// Every address has a mark in a Fenwick tree at the time of its
+// latest access. Counting the marks after an address's previous
+// access is the reuse distance. When the tree runs out of times,
+// the marks get packed down, so memory stays proportional to the
+// number of distinct addresses, not the number of accesses.
+type Reuse struct {
+	last   map[uintptr]int // address to time of its latest access
+	owner  []uintptr       // address accessed at each time
+	tree   []int32         // Fenwick tree of marks, 1-based
+	now    int
+	active int
+}
+
+// NewReuse returns a Reuse that hasn't seen any accesses.
+func NewReuse() *Reuse {
+	r := &Reuse{last: make(map[uintptr]int)}
+	r.grow(1024)
+	return r
+}
+
+// Access records an access to addr, and returns its reuse distance,
+// or -1 for the first access to addr.
+func (r *Reuse) Access(addr uintptr) int {
+	if r.now == len(r.owner) {
+		r.compact()
+	}
+	distance := -1
+	if prev, ok := r.last[addr]; ok {
+		distance = r.active - r.prefix(prev)
+		r.add(prev, -1)
+		r.active--
+	}
+	r.owner[r.now] = addr
+	r.last[addr] = r.now
+	r.add(r.now, 1)
+	r.active++
+	r.now++
+	return distance
+}
+
+// Distinct is the number of different addresses accessed so far.
+func (r *Reuse) Distinct() int {
+	return len(r.last)
+}
+
+// compact moves every address's mark down to consecutive times,
+// oldest first, with room for as many again to come.
+func (r *Reuse) compact() {
+	live := make([]uintptr, 0, r.active)
+	for t, addr := range r.owner[:r.now] {
+		if r.last[addr] == t {
+			live = append(live, addr)
+		}
+	}
+	r.grow(2*r.active + 1024)
+	for t, addr := range live {
+		r.owner[t] = addr
+		r.last[addr] = t
+		r.add(t, 1)
+	}
+	r.now = len(live)
+}
+
+func (r *Reuse) grow(times int) {
+	r.owner = make([]uintptr, times)
+	r.tree = make([]int32, times+1)
+}
+
+func (r *Reuse) add(t int, delta int32) {
+	for i := t + 1; i < len(r.tree); i += i & -i {
+		r.tree[i] += delta
+	}
+}
+
+// prefix counts the marks at times up to and including t.
+func (r *Reuse) prefix(t int) int {
+	sum := 0
+	for i := t + 1; i > 0; i -= i & -i {
+		sum += int(r.tree[i])
+	}
+	return sum
+}
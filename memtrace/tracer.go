@@ -0,0 +1,46 @@
+package memtrace
+
+import (
+	"unsafe"
+
+	"mergesort/listsort"
+	"mergesort/listsort/observed"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+// Tracer is an observed.Observer that writes a record of every
+// node read and write a sort does. A record's merge level is the
+// level of the latest split or merge the sort started, 0 before
+// the first one.
+type Tracer struct {
+	observed.NopObserver
+	w     *Writer
+	level int
+}
+
+// NewTracer returns a Tracer writing records to w.
+func NewTracer(w *Writer) *Tracer {
+	return &Tracer{w: w}
+}
+
+func (t *Tracer) Read(node *Node) {
+	t.w.Record(Read, t.level, uintptr(unsafe.Pointer(node)))
+}
+
+func (t *Tracer) Write(node *Node) {
+	t.w.Record(Write, t.level, uintptr(unsafe.Pointer(node)))
+}
+
+func (t *Tracer) Split(_ *Node, size int) {
+	t.level = Level(size)
+}
+
+func (t *Tracer) Merge(_ []*Node, sizes []int) {
+	size := 0
+	for _, s := range sizes {
+		size += s
+	}
+	t.level = Level(size)
+}
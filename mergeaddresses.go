@@ -1,74 +1,177 @@
 package main
 
 // Show the addresses of heads of merged lists,
-// same merged list in recursive and wikipedia bottom up
+// same merged list in any of the listsort mergesorts
 
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	"math/rand"
+	"math/rand/v2"
 	"os"
+	"strings"
 	"time"
 	"unsafe"
+
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/listsort/observed"
+	"mergesort/mergetree"
+	"mergesort/prng"
 )
 
 // Node is an element of a linked list
-type Node struct {
-	Data  uint
-	Reset *Node // another chain through the linked list
-	Next  *Node
+type Node = listsort.Node
+
+// rng chooses random numbers, seeded by the -seed flag
+var rng *rand.Rand
+
+// quiet stops printing a line per merge, set by the -q flag
+var quiet bool
+
+// printer is the observed.Observer of a sort. It prints the sizes
+// and head node addresses of each merge's lists, counts comparisons,
+// and records the merge tree.
+type printer struct {
+	*mergetree.Recorder
+	comparisons int
+}
+
+func (p *printer) Compare(_, _ *Node) { p.comparisons++ }
+
+func (p *printer) Merge(lists []*Node, sizes []int) {
+	if !quiet {
+		lengths := make([]string, len(sizes))
+		heads := make([]string, len(lists))
+		for i := range lists {
+			lengths[i] = fmt.Sprint(sizes[i])
+			heads[i] = fmt.Sprintf("%p", lists[i])
+		}
+		fmt.Printf("merging <%s> (%s)\n", strings.Join(lengths, ","), strings.Join(heads, ", "))
+	}
+	p.Recorder.Merge(lists, sizes)
 }
 
 func main() {
+	algorithms := flag.String("a", "all", "comma separated algorithms to show, or all: "+strings.Join(observed.Names, ", "))
+	minGallop := flag.Int("W", 7, "galloping merges gallop after this many wins in a row")
+	parallelDepth := flag.Int("d", 3, "parallel mergesort recursion depth using goroutines")
+	k := flag.Int("k", 4, "number of sublists the k-way mergesort merges")
 	countBegin := flag.Int("b", 64, "beginning list size")
+	keyFile := flag.String("f", "", "read data values from this file, - for stdin, instead of generating them")
+	dumpFile := flag.String("w", "", "write the list to this file, size appended to the name")
+	binaryKeys := flag.Bool("x", false, "-f and -w files hold 8-byte little-endian binary data values, not text")
+	treeFile := flag.String("t", "", "write each sort's merge tree to this file, algorithm and .dot or .json appended to the name")
+	flag.BoolVar(&quiet, "q", false, "don't print lists, or addresses of merged lists")
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano() | int64(os.Getpid()))
+	names, err := observed.Select(*algorithms)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := observed.Options{MinGallop: *minGallop, Depth: *parallelDepth, K: *k}
+	sorts := make([]func(*Node, observed.Observer) *Node, len(names))
+	for i, name := range names {
+		if sorts[i], err = observed.Lookup(name, opts); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	if rng, err = prng.New(*prngName, *seed); err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
+
+	var keys []uint
+	if *keyFile != "" {
+		if keys, err = listgen.ReadKeyFile(*keyFile, *binaryKeys); err != nil {
+			log.Fatal(err)
+		}
+		// list can't be longer than the file
+		*countBegin = min(*countBegin, len(keys))
+	}
+
 	hostname, _ := os.Hostname() // not going to fail
 	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
 	fmt.Printf("# List of %d nodes\n", *countBegin)
 	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
 
 	// 1. Create a list with randomly-chosen integer data values
-	// 2. Print the list, which shows data values
-	// 3. Do recursive merge sort on the list, printing out
-	//    addresses and sizes of lists to merge
+	// 2. For each algorithm, print the list, which shows data values
+	//    and that the list got reset to its original node ordering
+	// 3. Sort the list, printing out sizes and addresses of heads
+	//    of lists to merge
 	// 4. Check size and sortedness of list
 	// 5. Reset list to original node ordering
-	// 6. Print the list again, to allow check of list reset
-	// 7. Sort list via wikipedia bottom up algorithm,
-	//    printing out addresses and sizes of lists to merge
-	// 8. Check size and sortedness of list
 
-	head := randomValueList(*countBegin, *countBegin)
+	var head *Node
+	if keys != nil {
+		fmt.Printf("# data values read from %s\n", *keyFile)
+		head = listgen.KeyList(keys)(*countBegin, true)
+	} else {
+		head = randomValueList(*countBegin, *countBegin)
+	}
+	if *dumpFile != "" {
+		if err := listgen.WriteKeyFile(fmt.Sprintf("%s.%d", *dumpFile, *countBegin), head, *binaryKeys); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// original order of nodes restorable
+	order := listgen.NodeOrder(head)
 
-	var nl *Node
-	var sz int
-	fmt.Printf("# recursive sort\n")
-	fmt.Print("# ")
-	Print(head)
-	fmt.Println()
-	nl = recursiveMergeSort(head)
-	fmt.Printf("# %d comparisons during recursive merge sort of %d length list\n", recursiveComparisonCount, *countBegin)
-	checkSorted(nl, *countBegin, "first")
-	sz = resetList(head)
-	if sz != *countBegin {
-		log.Fatalf("list should be %d length, is %d after list reset\n", *countBegin, sz)
+	for i, sort := range sorts {
+		fmt.Printf("# %s sort\n", names[i])
+		if !quiet {
+			fmt.Print("# ")
+			listsort.Print(head)
+			fmt.Println()
+		}
+		p := &printer{Recorder: mergetree.NewRecorder(names[i], head)}
+		nl := sort(head, p)
+		writeTree(*treeFile, names[i], p.Recorder)
+		fmt.Printf("# %d comparisons during %s merge sort of %d length list\n", p.comparisons, names[i], *countBegin)
+		checkSorted(nl, *countBegin, names[i])
+		head = listgen.ResetList(order)
 	}
-	fmt.Printf("# bottom up sort\n")
-	fmt.Print("# ")
-	Print(head)
-	fmt.Println()
-	nl = buMergesort(head)
-	fmt.Printf("# %d comparisons during bottom up merge sort of %d length list\n", buComparisonCount, *countBegin)
-	checkSorted(nl, *countBegin, "second")
 
 	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
 }
 
+// writeTree writes the merge tree recorder has of the sort just done
+// as both DOT and JSON, if there's a file name to write to
+func writeTree(name, algorithm string, recorder *mergetree.Recorder) {
+	if name == "" {
+		return
+	}
+	tree := recorder.Tree()
+	for _, format := range []struct {
+		suffix string
+		write  func(io.Writer) error
+	}{{"dot", tree.WriteDOT}, {"json", tree.WriteJSON}} {
+		fileName := fmt.Sprintf("%s.%s.%s", name, algorithm, format.suffix)
+		fout, err := os.Create(fileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := format.write(fout); err != nil {
+			log.Fatal(err)
+		}
+		if err := fout.Close(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("# %s merge tree written to %s\n", algorithm, fileName)
+	}
+}
+
 func checkSorted(head *Node, nominalSize int, phrase string) {
-	if sz, sorted := isSorted(head); !sorted {
+	if sz, sorted := listsort.IsSorted(head); !sorted {
 		log.Printf("list of size %d not sorted at element %d, %s\n", nominalSize, sz, phrase)
 		os.Exit(1)
 	} else if sz != nominalSize {
@@ -77,220 +180,18 @@ func checkSorted(head *Node, nominalSize int, phrase string) {
 	}
 }
 
-// resetList walks the list that starts with head
-// via .Reset pointers. It sets .Next to .Reset
-// in every list node, restoring the original node
-// order.
-func resetList(head *Node) int {
-	length := 0
-	for node := head; node != nil; node = node.Reset {
-		node.Next = node.Reset
-		length++
-	}
-	return length
-}
-
-func isSorted(head *Node) (int, bool) {
-	if head == nil {
-		return 0, true
-	}
-	if head.Next == nil {
-		return 1, true
-	}
-	var sz int
-	for ; head.Next != nil; head = head.Next {
-		sz++
-		if head.Data > head.Next.Data {
-			return sz, false
-		}
-	}
-	sz++ // for-loop checks head.Next, count final element on list
-	return sz, true
-}
-
 // randomValueList creates a linked list with randomly-chosen
 // integer data values, up to max in size.
-// Two chains through the list, one via .Next pointers,
-// the other through .Reset pointers, which are used to reconstruct
-// the original order of the list
 func randomValueList(n int, max int) *Node {
 
 	var head *Node
 
 	for i := 0; i < n; i++ {
 		head = &Node{
-			Data:  uint(rand.Intn(max)),
-			Next:  head,
-			Reset: head, // original order of nodes restorable
+			Data: uint(rng.IntN(max)),
+			Next: head,
 		}
 	}
 
 	return head
 }
-
-var recursiveComparisonCount int
-
-func recursiveMergeSort(head *Node) *Node {
-	if head.Next == nil {
-		// single node list is sorted by definition
-		return head
-	}
-
-	// because of recursion bottoming out at a 1-long-list,
-	// head points to a list of at least 2 elements.
-
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
-		}
-	}
-
-	right := *turtle
-	*turtle = nil
-
-	left := recursiveMergeSort(head)
-	right = recursiveMergeSort(right)
-
-	leftLength, _ := isSorted(left)
-	rightLength, _ := isSorted(right)
-
-	fmt.Printf("merging <%d,%d> (%p, %p)\n", leftLength, rightLength, left, right)
-
-	// Set h, t variables so that the loop doing the merge
-	// does not have to have a "if h == nil" check every iteration.
-	x := &right
-	recursiveComparisonCount++
-	if left.Data < right.Data {
-		x = &left
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
-	// than the other. Have to check both for nil.
-	for left != nil && right != nil {
-		n := &right
-		recursiveComparisonCount++
-		if left.Data < right.Data {
-			n = &left
-		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
-		// At the end of this for-loop, t.Next ends up being nil
-		// because of the left/right list splitting.
-	}
-
-	// Either left or right are nil. If left == nil,
-	// assigning nil to t.Next is no issue.
-	t.Next = left
-	if right != nil {
-		// but if right is nil, can't assign nil to t.Next,
-		// because left was non-nil.
-		t.Next = right
-	}
-
-	return h
-}
-
-func merge(p *Node, q *Node) *Node {
-	if p == nil {
-		return q
-	}
-	if q == nil {
-		return p
-	}
-
-	x := &q
-	buComparisonCount++
-	if p.Data < q.Data {
-		x = &p
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	for p != nil && q != nil {
-		n := &q
-		buComparisonCount++
-		if p.Data < q.Data {
-			n = &p
-		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
-	}
-
-	t.Next = p
-	if q != nil {
-		t.Next = q
-	}
-
-	return h
-}
-
-// Print runs a linked list and prints its values on stdout
-func Print(list *Node) {
-	for node := list; node != nil; node = node.Next {
-		fmt.Printf("%d -> ", node.Data)
-	}
-	fmt.Println()
-}
-
-var buComparisonCount int
-
-// buMergesort - transliteration of Wikipedia's "Bottom up implementation with lists",
-// https://en.wikipedia.org/wiki/Merge_sort#Bottom-up_implementation_using_lists
-func buMergesort(head *Node) *Node {
-	if head == nil {
-		return nil
-	}
-	// Can pass 1-length lists to the rest of the function,
-	// because array[0] is 2^0 or 1 in size
-
-	var array [32]*Node
-	var result, next *Node
-	var i int
-
-	result = head
-
-	for result != nil {
-		next = result.Next
-		result.Next = nil
-
-		for i = 0; (i < 32) && (array[i] != nil); i++ {
-			resultLength, _ := isSorted(result)
-			iLength, _ := isSorted(array[i])
-			fmt.Printf("merging <%d,%d> (%p, %p)\n", resultLength, iLength, array[i], result)
-			result = merge(array[i], result)
-			array[i] = nil
-		}
-		if i == 32 {
-			i--
-		}
-		array[i] = result
-		result = next
-	}
-
-	result = nil
-	for i = 0; i < 32; i++ {
-		resultLength, _ := isSorted(result)
-		iLength, _ := isSorted(array[i])
-		phrase := "nil"
-		if array[i] != nil {
-			phrase = "non-nil"
-		}
-		fmt.Printf("%d %s, merging <%d,%d> (%p, %p)\n", i, phrase, resultLength, iLength, array[i], result)
-		result = merge(array[i], result)
-	}
-
-	return result
-}
@@ -1,46 +1,163 @@
 package main
 
 import (
-	crand "crypto/rand"
+	"cmp"
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	"math"
-	"math/big"
-	"math/rand"
 	"os"
 	"runtime"
 	"time"
 	"unsafe"
+
+	"mergesort/extsort"
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/listsort/generic"
+	"mergesort/listsort/observed"
+	"mergesort/memtrace"
+	"mergesort/prng"
 )
 
 // Node is an element of a linked list
-type Node struct {
-	Data uint
-	Next *Node
-}
+type Node = listsort.Node
 
 func main() {
 	useCryptoRand := flag.Bool("c", false, "use cryptographic PRNG")
 	useRecursiveSort := flag.Bool("r", false, "use purely recursive mergesort")
 	useRecursiveSort2 := flag.Bool("z", false, "use purely recursive mergesort with user stack")
 	useBottomUp := flag.Bool("B", false, "bottom-up mergesort with lists")
+	useListSort := flag.Bool("L", false, "port of Linux kernel list_sort")
+	useNatural := flag.Bool("n", false, "natural mergesort, merging ascending runs")
+	reverseRuns := flag.Bool("D", false, "natural mergesort also reverses descending runs")
+	minGallop := flag.Int("W", 0, "-r or -B galloping merge after this many wins in a row, 0 never gallops")
+	useGeneric := flag.Bool("g", false, "use generic, comparison function version of sort")
+	useParallel := flag.Bool("P", false, "parallel recursive mergesort with goroutines")
+	parallelDepth := flag.Int("d", 3, "parallel mergesort recursion depth using goroutines")
+	maxProcs := flag.Int("p", 0, "set GOMAXPROCS, 0 leaves it at its default")
+	doublyLinked := flag.Bool("2", false, "sort doubly linked lists, compare with singly linked sorts of the same lists")
+	circularList := flag.Bool("O", false, "sort circular lists, starting at a random node")
+	useArena := flag.Bool("A", false, "allocate each list size's nodes from an arena, like mergetest.c -p")
+	displaced := flag.Float64("X", 0, "locality list, fraction of nodes displaced from memory order")
+	shuffleBlock := flag.Int("K", 0, "locality list, shuffle memory order in blocks of this many nodes")
+	maxJump := flag.Int("J", 0, "locality list, move nodes up to this many positions from memory order")
+	hugePages := flag.String("H", "", "allocate nodes from an mmap arena, madvise huge, nohuge or none")
+	keyFile := flag.String("f", "", "read data values from this file, - for stdin, instead of generating them")
+	dumpFile := flag.String("w", "", "write the first list of each size to this file, size appended to the name")
+	binaryKeys := flag.Bool("x", false, "-f and -w files hold 8-byte little-endian binary data values, not text")
+	traceFile := flag.String("M", "", "trace node reads and writes of an extra, untimed sort of each size to this file, size appended to the name")
+	usePool := flag.Bool("F", false, "free sorted lists to a node pool, re-use pooled nodes")
+	nodeSize := flag.Int("N", 16, "node size in bytes, data value, .Next pointer and padding")
 	reuseList := flag.Bool("R", false, "re-randomize and re-use list")
 	alreadySorted := flag.Bool("s", false, "already sorted low-to-high list")
 	reverseSorted := flag.Bool("S", false, "reverse sorted high-to-low list")
+	distribution := flag.String("v", "", "data value distribution: "+listgen.Distributions)
 	addressOrderedList := flag.Bool("m", false, "create address-ordered list for each sort")
 	garbageCollectAfter := flag.Bool("G", false, "collect garbage after each sort")
+	checkStability := flag.Bool("t", false, "check that equal data values keep their original order")
+	externalBudget := flag.Int("E", 0, "external mergesort with this memory budget, MiB")
+	tempDir := flag.String("T", os.TempDir(), "directory for external mergesort run files")
 	countIncrement := flag.Int("i", 200000, "increment of list size")
 	countBegin := flag.Int("b", 1000, "beginning list size")
 	countUntil := flag.Int("u", 18000000, "sort lists up to this size")
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano() | int64(os.Getpid()))
+	// check every flag combination before changing any
+	// allocator state or writing any output
 	if *useRecursiveSort && *useBottomUp {
 		log.Fatalf("only one of -r and -B allowed\n")
 	}
+	if *useGeneric && *useParallel {
+		log.Fatalf("no generic version of -P sort\n")
+	}
+	if *reverseRuns && !*useNatural {
+		log.Fatalf("-D reverses descending runs only with -n\n")
+	}
+	if *minGallop > 0 && !*useRecursiveSort && !*useBottomUp {
+		log.Fatalf("-W galloping merge only with -r or -B\n")
+	}
+	if *doublyLinked && (*useRecursiveSort2 || *useNatural || *useParallel || *useListSort || *useGeneric || *minGallop > 0) {
+		log.Fatalf("doubly linked lists only with the iterative, -r or -B sorts\n")
+	}
+	if *doublyLinked && (*reuseList || *checkStability || *externalBudget > 0) {
+		log.Fatalf("-2 doesn't allow -R, -t or -E\n")
+	}
+	if *circularList && (*doublyLinked || *reuseList || *checkStability || *externalBudget > 0) {
+		log.Fatalf("-O doesn't allow -2, -R, -t or -E\n")
+	}
+	if *nodeSize != int(unsafe.Sizeof(Node{})) && (*doublyLinked || *externalBudget > 0) {
+		log.Fatalf("-N doesn't allow -2 or -E\n")
+	}
+	if *useArena && (*doublyLinked || *externalBudget > 0) {
+		log.Fatalf("-A doesn't allow -2 or -E\n")
+	}
+	if *usePool && (*useArena || *reuseList || *doublyLinked || *circularList || *externalBudget > 0) {
+		log.Fatalf("-F doesn't allow -A, -R, -2, -O or -E\n")
+	}
+	if *hugePages != "" && (*useArena || *usePool || *doublyLinked || *externalBudget > 0) {
+		log.Fatalf("-H doesn't allow -A, -F, -2 or -E\n")
+	}
+	localityList := *displaced > 0 || *shuffleBlock > 1 || *maxJump > 0
+	if localityList && (*addressOrderedList || *alreadySorted || *reverseSorted || *useArena || *usePool ||
+		*hugePages != "" || *externalBudget > 0) {
+		log.Fatalf("-X, -K and -J don't allow -m, -s, -S, -A, -F, -H or -E\n")
+	}
+	if *externalBudget > 0 && (*reuseList || *addressOrderedList || *checkStability) {
+		log.Fatalf("external mergesort doesn't allow -R, -m or -t\n")
+	}
+	if *distribution != "" && (*alreadySorted || *reverseSorted || *addressOrderedList || localityList || *externalBudget > 0 || *reuseList) {
+		log.Fatalf("-v doesn't allow -s, -S, -m, -X, -K, -J, -E or -R\n")
+	}
+	if *keyFile != "" && (*alreadySorted || *reverseSorted || *addressOrderedList || localityList || *distribution != "" || *reuseList) {
+		log.Fatalf("-f doesn't allow -s, -S, -m, -X, -K, -J, -v or -R\n")
+	}
+	if *dumpFile != "" && (*externalBudget > 0 || *reuseList) {
+		log.Fatalf("-w doesn't allow -E or -R\n")
+	}
+	if *traceFile != "" && (*useGeneric || *doublyLinked || *circularList || *externalBudget > 0 || *reuseList) {
+		log.Fatalf("-M doesn't allow -g, -2, -O, -E or -R\n")
+	}
+	if *traceFile != "" && *nodeSize > memtrace.MaxNodeSize {
+		log.Fatalf("-M traces nodes of at most %d bytes, -N %d is too big\n", memtrace.MaxNodeSize, *nodeSize)
+	}
+	var distributionCreation func(int, bool) *Node
+	var distributionPhrase string
+	if *distribution != "" {
+		var err error
+		if distributionCreation, distributionPhrase, err = listgen.Distribution(*distribution); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
+	if err := listgen.SetNodeSize(*nodeSize); err != nil {
+		log.Fatal(err)
+	}
+	if *maxProcs > 0 {
+		runtime.GOMAXPROCS(*maxProcs)
+	}
+	var keys []uint
+	if *keyFile != "" {
+		var err error
+		if keys, err = listgen.ReadKeyFile(*keyFile, *binaryKeys); err != nil {
+			log.Fatal(err)
+		}
+		// lists can't be longer than the file
+		*countUntil = min(*countUntil, len(keys)+1)
+	}
 	hostname, _ := os.Hostname() // not going to fail
 	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
 	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
 		*countBegin, *countUntil, *countIncrement)
 	sortType := "iterative"
@@ -50,72 +167,350 @@ func main() {
 		sortType = "bottom-up iterative"
 	} else if *useRecursiveSort2 {
 		sortType = "recursive with user-level stack"
+	} else if *useNatural {
+		sortType = "natural"
+	} else if *useParallel {
+		sortType = "parallel recursive"
+	} else if *useListSort {
+		sortType = "Linux kernel list_sort bottom-up"
 	}
 	fmt.Printf("# %s sort\n", sortType)
+	if *useNatural && *reverseRuns {
+		fmt.Println("# reverse descending runs")
+	}
+	if *minGallop > 0 {
+		fmt.Printf("# galloping merges, gallop after %d wins in a row\n", *minGallop)
+	}
+	if *useParallel {
+		fmt.Printf("# goroutines to recursion depth %d, GOMAXPROCS %d, %d CPUs\n",
+			*parallelDepth, runtime.GOMAXPROCS(0), runtime.NumCPU())
+	}
+	if *useGeneric {
+		fmt.Println("# generic sort, cmp.Compare comparison function")
+	}
+	if *doublyLinked {
+		fmt.Printf("# doubly linked list, nodes %d bytes in size\n", unsafe.Sizeof(listsort.DNode{}))
+		fmt.Println("# final column is mean time of the singly linked sort of the same data values")
+	}
 	listType := "idomatic"
 	if *addressOrderedList {
 		listType = "memory address"
 	}
+	if localityList {
+		listType = "controlled locality"
+	}
 	fmt.Printf("# %s list in-memory ordering\n", listType)
+	if *useArena {
+		fmt.Println("# nodes allocated from an arena of slabs, reset after each sort")
+	}
+	if *usePool {
+		fmt.Println("# sorted lists freed to a node pool, pooled nodes re-used")
+	}
+	if *hugePages != "" {
+		fmt.Printf("# nodes allocated from an mmap arena, madvise %s, transparent huge pages %s\n",
+			*hugePages, listgen.THPEnabled())
+	}
+	if *circularList {
+		fmt.Println("# circular list, random start node")
+	}
 	if *reuseList {
 		fmt.Println("# re-random-value and re-use list")
 	}
 	if *garbageCollectAfter {
 		fmt.Println("# garbage collect after each sort iteration")
 	}
-	randomType := "math/rand"
+	if *checkStability {
+		fmt.Println("# check sort stability")
+	}
+	if *externalBudget > 0 {
+		fmt.Printf("# external mergesort, %d MiB memory budget, %d nodes per run, run files in %s\n",
+			*externalBudget, (*externalBudget<<20)/int(unsafe.Sizeof(Node{})), *tempDir)
+	}
+	randomType := *prngName
 	if *useCryptoRand {
 		randomType = "cryptographic"
 	}
 	fmt.Printf("# %s random numbers as list node values\n", randomType)
-	fmt.Printf("# nodes %d bytes in size, alignment %d\n", unsafe.Sizeof(Node{}), unsafe.Alignof(Node{}))
+	fmt.Printf("# nodes %d bytes in size, alignment %d\n", listgen.NodeSize(), unsafe.Alignof(Node{}))
 
 	var listCreation func(int, bool) *Node
-	listCreation = randomValueList
+	listCreation = listgen.RandomValueList
 	listCreationPhrase := "randomly chosen data"
 	if *addressOrderedList {
-		listCreation = memoryOrderedList
+		listCreation = listgen.MemoryOrderedList
 		listCreationPhrase = "unordered"
 		fmt.Printf("# node addresses ascending in memory\n")
 	}
+	if localityList {
+		listCreation = listgen.LocalityList(listgen.Disorder{
+			Block:    *shuffleBlock,
+			MaxJump:  *maxJump,
+			Fraction: *displaced,
+		})
+		fmt.Printf("# nodes in one slab, shuffled in blocks of %d, moved up to %d positions, %g displaced\n",
+			*shuffleBlock, *maxJump, *displaced)
+	}
 	if *alreadySorted {
-		listCreation = presortedList
+		listCreation = listgen.PresortedList
 		listCreationPhrase = "presorted"
 	}
 	if *reverseSorted {
-		listCreation = reverseSortedList
+		listCreation = listgen.ReverseSortedList
 		listCreationPhrase = "reverse sorted"
 	}
+	if distributionCreation != nil {
+		listCreation, listCreationPhrase = distributionCreation, distributionPhrase
+	}
+	if keys != nil {
+		listCreation = listgen.KeyList(keys)
+		listCreationPhrase = "file"
+		fmt.Printf("# %d data values read from %s\n", len(keys), *keyFile)
+	}
 	fmt.Printf("# %s data values\n", listCreationPhrase)
+	if *dumpFile != "" {
+		// write out the first list of each size, before it's sorted
+		createList, dumped := listCreation, -1
+		listCreation = func(n int, useCheapRand bool) *Node {
+			head := createList(n, useCheapRand)
+			if n != dumped {
+				dumped = n
+				if err := listgen.WriteKeyFile(fmt.Sprintf("%s.%d", *dumpFile, n), head, *binaryKeys); err != nil {
+					log.Fatal(err)
+				}
+			}
+			return head
+		}
+		fmt.Printf("# first list of each size written to %s.<size>\n", *dumpFile)
+	}
+
+	var sortList func(*Node) *Node
+	switch {
+	case *useGeneric && *useRecursiveSort && *minGallop > 0:
+		sortList = func(head *Node) *Node {
+			return generic.RecursiveMergeSortGalloping(head, *minGallop, cmp.Compare[uint])
+		}
+	case *useGeneric && *useBottomUp && *minGallop > 0:
+		sortList = func(head *Node) *Node {
+			return generic.BUMergesortGalloping(head, *minGallop, cmp.Compare[uint])
+		}
+	case *useGeneric && *useRecursiveSort:
+		sortList = func(head *Node) *Node {
+			return generic.RecursiveMergeSort(head, cmp.Compare[uint])
+		}
+	case *useGeneric && *useBottomUp:
+		sortList = func(head *Node) *Node {
+			return generic.BUMergesort(head, cmp.Compare[uint])
+		}
+	case *useGeneric && *useRecursiveSort2:
+		sortList = func(head *Node) *Node {
+			return generic.OwnstackMergeSort(head, cmp.Compare[uint])
+		}
+	case *useGeneric && *useListSort:
+		sortList = func(head *Node) *Node {
+			return generic.ListSort(head, cmp.Compare[uint])
+		}
+	case *useGeneric && *useNatural && *reverseRuns:
+		sortList = func(head *Node) *Node {
+			return generic.NaturalMergesortReversing(head, cmp.Compare[uint])
+		}
+	case *useGeneric && *useNatural:
+		sortList = func(head *Node) *Node {
+			return generic.NaturalMergesort(head, cmp.Compare[uint])
+		}
+	case *useGeneric:
+		sortList = func(head *Node) *Node {
+			return generic.Mergesort(head, cmp.Compare[uint])
+		}
+	case *useRecursiveSort && *minGallop > 0:
+		sortList = func(head *Node) *Node {
+			return listsort.RecursiveMergeSortGalloping(head, *minGallop)
+		}
+	case *useBottomUp && *minGallop > 0:
+		sortList = func(head *Node) *Node {
+			return listsort.BUMergesortGalloping(head, *minGallop)
+		}
+	case *useRecursiveSort:
+		sortList = listsort.RecursiveMergeSort
+	case *useRecursiveSort2:
+		sortList = listsort.OwnstackMergeSort
+	case *useBottomUp:
+		sortList = listsort.BUMergesort
+	case *useListSort:
+		sortList = listsort.ListSort
+	case *useNatural && *reverseRuns:
+		sortList = listsort.NaturalMergesortReversing
+	case *useNatural:
+		sortList = listsort.NaturalMergesort
+	case *useParallel:
+		sortList = func(head *Node) *Node {
+			return listsort.ParallelMergeSort(head, *parallelDepth)
+		}
+	default:
+		sortList = listsort.Mergesort
+	}
+
+	var traceList func(*Node, observed.Observer) *Node
+	if *traceFile != "" {
+		// the observed version of the sort sortList does
+		var traceName string
+		switch {
+		case *useRecursiveSort && *minGallop > 0:
+			traceName = "recursivegallop"
+		case *useBottomUp && *minGallop > 0:
+			traceName = "bottomupgallop"
+		case *useRecursiveSort:
+			traceName = "recursive"
+		case *useRecursiveSort2:
+			traceName = "ownstack"
+		case *useBottomUp:
+			traceName = "bottomup"
+		case *useListSort:
+			traceName = "listsort"
+		case *useNatural && *reverseRuns:
+			traceName = "naturalreversing"
+		case *useNatural:
+			traceName = "natural"
+		case *useParallel:
+			traceName = "parallel"
+		default:
+			traceName = "iterative"
+		}
+		var err error
+		if traceList, err = observed.Lookup(traceName, observed.Options{MinGallop: *minGallop, Depth: *parallelDepth}); err != nil {
+			log.Fatal(err)
+		}
+		// the traced list is the first of its size, the one -w writes
+		fmt.Printf("# node reads and writes of an extra, untimed %s sort of each size traced to %s.<size>\n", traceName, *traceFile)
+	}
+
+	isSorted := listsort.IsSorted
+	if *circularList {
+		isSorted = listsort.IsSortedRing
+		// Break the ring, sort, close the ring again.
+		sortLinear, createLinear := sortList, listCreation
+		sortList = func(start *Node) *Node {
+			return listsort.SortRing(start, sortLinear)
+		}
+		listCreation = func(n int, useCheapRand bool) *Node {
+			if n == 0 {
+				return nil
+			}
+			return listgen.Ring(createLinear(n, useCheapRand), rng.IntN(n))
+		}
+	}
+
+	sortDoubly := listsort.DoublyMergesort
+	if *useRecursiveSort {
+		sortDoubly = listsort.DoublyRecursiveMergeSort
+	} else if *useBottomUp {
+		sortDoubly = listsort.DoublyBUMergesort
+	}
+
+	// dataValue gives external mergesort the data value of
+	// node i of n, without having to create a list of n nodes.
+	dataValue := func(i, n int) uint {
+		return listgen.RandomValue(!*useCryptoRand)
+	}
+	if *alreadySorted {
+		dataValue = func(i, n int) uint { return uint(i) }
+	}
+	if *reverseSorted {
+		dataValue = func(i, n int) uint { return uint(n - 1 - i) }
+	}
+	if keys != nil {
+		dataValue = func(i, n int) uint { return keys[i] }
+	}
+
+	var pool *listgen.Pool
+	if *usePool {
+		pool = &listgen.Pool{}
+		listgen.UsePool(pool)
+	}
 
 	for n := *countBegin; n < *countUntil; n += *countIncrement {
 		var total time.Duration
 		var looping time.Duration
+		var bytesWritten, bytesRead int64
+		var singly time.Duration
+		var arena *listgen.Arena
+		if *useArena {
+			// one slab big enough for the whole list, like perform_preallocation
+			arena = listgen.NewArena(n)
+			listgen.UseArena(arena)
+		}
+		var mmapArena *listgen.MmapArena
+		if *hugePages != "" {
+			var err error
+			if mmapArena, err = listgen.NewMmapArena(n, *hugePages); err != nil {
+				log.Fatal(err)
+			}
+			listgen.UseMmapArena(mmapArena)
+		}
+		if traceList != nil {
+			nl := traceSort(fmt.Sprintf("%s.%d", *traceFile, n), listCreation(n, !*useCryptoRand), traceList)
+			if sz, sorted := isSorted(nl); !sorted || sz != n {
+				log.Printf("traced list of size %d not sorted at element %d, or wrong size\n", n, sz)
+				os.Exit(1)
+			}
+			if arena != nil {
+				arena.Reset()
+			} else if mmapArena != nil {
+				mmapArena.Reset()
+			} else if pool != nil {
+				pool.Free(nl)
+			}
+		}
 		var head *Node
 		if *reuseList {
-			head = listCreation(n, *useCryptoRand)
+			head = listCreation(n, !*useCryptoRand)
 		}
 		min := time.Duration((365 * 24 * 3600) * time.Second)
 		max := time.Duration(0)
 		for i := 0; i < 10; i++ {
 			beforeIteration := time.Now()
+			if *externalBudget > 0 {
+				elapsed, written, read := externalSort(n, dataValue, sortList, int64(*externalBudget)<<20, *tempDir)
+				total += elapsed
+				if elapsed > max {
+					max = elapsed
+				}
+				if elapsed < min {
+					min = elapsed
+				}
+				bytesWritten += written
+				bytesRead += read
+				looping += time.Since(beforeIteration)
+				continue
+			}
+			if *doublyLinked {
+				elapsed, singlyElapsed := doublySort(n, listCreation(n, !*useCryptoRand), sortDoubly, sortList)
+				total += elapsed
+				if elapsed > max {
+					max = elapsed
+				}
+				if elapsed < min {
+					min = elapsed
+				}
+				singly += singlyElapsed
+				if *garbageCollectAfter {
+					runtime.GC()
+				}
+				looping += time.Since(beforeIteration)
+				continue
+			}
 			if !*reuseList {
 				// fresh, new list every iteration
-				head = listCreation(n, *useCryptoRand)
+				head = listCreation(n, !*useCryptoRand)
+			}
+
+			var positions map[*Node]int
+			if *checkStability {
+				positions = listsort.Positions(head)
 			}
 
 			var nl *Node
 			before := time.Now()
-			switch {
-			case *useRecursiveSort:
-				nl = recursiveMergeSort(head)
-			case *useRecursiveSort2:
-				nl = ownstackMergeSort(head)
-			case *useBottomUp:
-				nl = buMergesort(head)
-			default:
-				nl = mergesort(head)
-			}
+			nl = sortList(head)
 			elapsed := time.Since(before)
 			total += elapsed
 			if elapsed > max {
@@ -132,9 +527,22 @@ func main() {
 				log.Printf("list of size %d had %d elements after sort\n", n, sz)
 				os.Exit(2)
 			}
+			if *checkStability {
+				if sz, stable := listsort.IsStable(nl, positions); !stable {
+					log.Printf("list of size %d not stable at element %d\n", n, sz)
+					os.Exit(3)
+				}
+				positions = nil
+			}
 
 			if *reuseList {
-				head = rerandomizeList(nl, *useCryptoRand)
+				head = listgen.RerandomizeList(nl, !*useCryptoRand)
+			} else if arena != nil {
+				arena.Reset()
+			} else if mmapArena != nil {
+				mmapArena.Reset()
+			} else if pool != nil {
+				pool.Free(nl)
 			}
 
 			if *garbageCollectAfter {
@@ -146,411 +554,161 @@ func main() {
 			looping += elapsed
 		}
 		total /= 10.0
-		fmt.Printf("%d\t%.04f\t%.04f\t%.04f\t%.04f\n", n, total.Seconds(), looping.Seconds(), min.Seconds(), max.Seconds())
-	}
-
-	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
-}
-
-func isSorted(head *Node) (int, bool) {
-	if head == nil {
-		return 0, true
-	}
-	if head.Next == nil {
-		return 1, true
-	}
-	var sz int
-	for ; head.Next != nil; head = head.Next {
-		sz++
-		if head.Data > head.Next.Data {
-			return sz, false
+		if mmapArena != nil {
+			head = nil
+			if err := mmapArena.Close(); err != nil {
+				log.Fatal(err)
+			}
 		}
-	}
-	sz++ // for-loop checks head.Next, count final element on list
-	return sz, true
-}
-
-var maxInt = big.NewInt(math.MaxInt32)
-
-func randomValueList(n int, useCheapRand bool) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		head = &Node{
-			Data: randomValue(useCheapRand),
-			Next: head,
+		if *externalBudget > 0 {
+			fmt.Printf("%d\t%.04f\t%.04f\t%.04f\t%.04f\t%d\t%d\n", n, total.Seconds(), looping.Seconds(), min.Seconds(), max.Seconds(),
+				bytesWritten/10, bytesRead/10)
+			continue
 		}
-	}
-
-	return head
-}
-
-func presortedList(n int, _ bool) *Node {
-
-	var head *Node
-
-	for i := n - 1; i >= 0; i-- {
-		head = &Node{
-			Data: uint(i),
-			Next: head,
+		if pool != nil {
+			fmt.Printf("# %d nodes: node pool %d hits, %d misses\n", n, pool.Hits, pool.Misses)
+			pool.Hits, pool.Misses = 0, 0
 		}
-	}
-
-	return head
-}
-
-func reverseSortedList(n int, _ bool) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		head = &Node{
-			Data: uint(i),
-			Next: head,
+		if *doublyLinked {
+			fmt.Printf("%d\t%.04f\t%.04f\t%.04f\t%.04f\t%.04f\n", n, total.Seconds(), looping.Seconds(), min.Seconds(), max.Seconds(),
+				(singly / 10).Seconds())
+			continue
 		}
+		fmt.Printf("%d\t%.04f\t%.04f\t%.04f\t%.04f\n", n, total.Seconds(), looping.Seconds(), min.Seconds(), max.Seconds())
 	}
 
-	return head
+	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
 }
 
-func memoryOrderedList(n int, useCheapRand bool) *Node {
+// doublySort copies a singly linked list into a doubly linked list,
+// then times sorting the singly linked list with sortList, and the
+// doubly linked list with sortDoubly, the same algorithm keeping
+// .Prev pointers right as well. It returns the doubly linked sort's
+// elapsed time, then the singly linked sort's.
+func doublySort(n int, head *Node, sortDoubly func(*listsort.DNode) *listsort.DNode, sortList func(*Node) *Node) (time.Duration, time.Duration) {
+	dhead := listgen.DoublyLinked(head)
 
-	head := &Node{}
-	head.Data = uint(uintptr(unsafe.Pointer(head)))
-	tail := head
+	before := time.Now()
+	head = sortList(head)
+	singly := time.Since(before)
 
-	// Append new *Node to end of list - this will create
-	// a list that has blocks of nodes in descending address order
-	for i := 1; i < n; i++ {
-		nn := &Node{}
-		nn.Data = uint(uintptr(unsafe.Pointer(nn)))
-		tail.Next = nn
-		tail = tail.Next
+	if sz, sorted := listsort.IsSorted(head); !sorted || sz != n {
+		log.Printf("singly linked list of size %d not sorted at element %d, or wrong size\n", n, sz)
+		os.Exit(1)
 	}
 
-	// sort all nodes by address, so that even blocks of nodes are
-	// ordered by ascending address.
-	head = recursiveMergeSort(head)
-	return rerandomizeList(head, useCheapRand)
-}
+	before = time.Now()
+	dhead = sortDoubly(dhead)
+	elapsed := time.Since(before)
 
-func rerandomizeList(head *Node, useCheapRand bool) *Node {
-	for node := head; node != nil; node = node.Next {
-		node.Data = randomValue(useCheapRand)
-	}
-	return head
-}
+	checkDoubly(dhead, n)
 
-func randomValue(useCheapRand bool) uint {
-	var ri int
-	if useCheapRand {
-		ri = rand.Int()
-	} else {
-		mp, err := crand.Int(crand.Reader, maxInt)
-		if err != nil {
-			log.Fatal(err)
-		}
-		ri = int(mp.Int64())
-	}
-	return uint(ri)
+	return elapsed, singly
 }
 
-func mergesort(head *Node) *Node {
-
-	var hd, tl *Node
-	appnd := func(n *Node) {
-		if hd == nil {
-			hd = n
-			tl = n
-			return
-		}
-		tl.Next = n
-		tl = n
+func checkDoubly(head *listsort.DNode, n int) {
+	if sz, sorted := listsort.IsDoublySorted(head); !sorted {
+		log.Printf("doubly linked list of size %d not sorted at element %d\n", n, sz)
+		os.Exit(1)
+	} else if sz != n {
+		log.Printf("doubly linked list of size %d had %d elements after sort\n", n, sz)
+		os.Exit(2)
 	}
-
-	p := head
-	mergecount := 2 // just to pass the first for-test
-
-	// The final pass over the unsorted linked list merges
-	// two lists each of about half the number of nodes.
-	// mergecount will have value 1 in that case. Don't
-	// need to loop again.
-	for k := 1; mergecount > 1; k *= 2 {
-
-		mergecount = 0
-
-		for p != nil {
-
-			psize := 0
-			q := p
-			for i := 0; q != nil && i < k; i++ {
-				psize++
-				q = q.Next
-			}
-
-			qsize := psize
-
-			for psize > 0 && qsize > 0 && q != nil {
-				if p.Data < q.Data {
-					appnd(p)
-					p = p.Next
-					psize--
-					continue
-				}
-				appnd(q)
-				q = q.Next
-				qsize--
-			}
-
-			for ; psize > 0 && p != nil; psize-- {
-				appnd(p)
-				p = p.Next
-			}
-
-			for ; qsize > 0 && q != nil; qsize-- {
-				appnd(q)
-				q = q.Next
-			}
-
-			p = q
-
-			mergecount++
-		}
-
-		p = hd
-		head = hd
-
-		hd = nil
-		tl.Next = nil
-		tl = nil
-	}
-
-	return head
 }
 
-func recursiveMergeSort(head *Node) *Node {
-	if head.Next == nil {
-		// single node list is sorted by definition
-		return head
+// traceSort sorts the list at head with sortList, writing a trace
+// of the node reads and writes it does to a file named fileName.
+func traceSort(fileName string, head *Node, sortList func(*Node, observed.Observer) *Node) *Node {
+	fout, err := os.Create(fileName)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	// because of recursion bottoming out at a 1-long-list,
-	// head points to a list of at least 2 elements.
-
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
-		}
+	w, err := memtrace.NewWriter(fout, listgen.NodeSize())
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	right := *turtle
-	*turtle = nil
-
-	left := recursiveMergeSort(head)
-	right = recursiveMergeSort(right)
-
-	// Set h, t variables so that the loop doing the merge
-	// does not have to have a "if h == nil" check every iteration.
-	x := &right
-	if left.Data < right.Data {
-		x = &left
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
-	// than the other. Have to check both for nil.
-	for left != nil && right != nil {
-		n := &right
-		if left.Data < right.Data {
-			n = &left
-		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
-		// At the end of this for-loop, t.Next ends up being nil
-		// because of the left/right list splitting.
+	nl := sortList(head, memtrace.NewTracer(w))
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
 	}
-
-	// Either left or right are nil. If left == nil,
-	// assigning nil to t.Next is no issue.
-	t.Next = left
-	if right != nil {
-		// but if right is nil, can't assign nil to t.Next,
-		// because left was non-nil.
-		t.Next = right
+	if err := fout.Close(); err != nil {
+		log.Fatal(err)
 	}
-
-	return h
+	return nl
 }
 
-type stackFrame struct {
-	list   *Node
-	merged *Node
-	next   *stackFrame
-}
-
-func split(head *Node) (*Node, *Node) {
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
+// externalSort sorts n data values with an external mergesort,
+// writing the sorted values to a temporary output file, then
+// checks the output file. It returns the elapsed time of the sort,
+// not counting creating data values, and the bytes of run files
+// and output the sort wrote and read.
+func externalSort(n int, dataValue func(int, int) uint, sortList func(*Node) *Node, budget int64, dir string) (time.Duration, int64, int64) {
+	sorter := extsort.NewSorter(dir, budget, sortList)
+	defer sorter.Close()
+
+	// Create data values in batches, outside of the timed code
+	var elapsed time.Duration
+	batch := make([]uint, 0, 4096)
+	for i := 0; i < n; {
+		batch = batch[:0]
+		for ; i < n && len(batch) < cap(batch); i++ {
+			batch = append(batch, dataValue(i, n))
 		}
-	}
-	right := *turtle
-	*turtle = nil
-	return head, right
-}
-
-func ownstackMergeSort(head *Node) *Node {
-
-	stack := &stackFrame{
-		list: head,
-	}
-
-	var sorted *Node
-
-	for {
-		var elem *stackFrame
-
-		elem, stack = stack, stack.next
-
-		if elem.list == nil && stack == nil {
-			sorted = elem.merged
-			break
-		}
-
-		if elem.list != nil && elem.list.Next == nil {
-			// "recursion" has bottomed out at 1-node list
-			elem.merged, elem.list = elem.list, nil
-			elem.next, stack = stack, elem
-			continue
-		}
-
-		if elem.merged != nil {
-			// a merged sublist has "returned"
-
-			tmp := stack
-			stack = stack.next
-
-			if tmp.merged == nil {
-				elem.next, stack = stack, elem
-				tmp.next, stack = stack, tmp
-				continue
+		before := time.Now()
+		for _, value := range batch {
+			if err := sorter.Add(value); err != nil {
+				log.Fatal(err)
 			}
-
-			// both tmp and elem contain merged sublists
-
-			elem.merged = merge(elem.merged, tmp.merged)
-			elem.next, stack = stack, elem
-			// discarding tmp
-			continue
-		}
-
-		// still "recursing"
-		left, right := split(elem.list)
-		stack = &stackFrame{
-			list: left,
-			next: stack,
-		}
-		stack = &stackFrame{
-			list: right,
-			next: stack,
 		}
+		elapsed += time.Since(before)
 	}
 
-	return sorted
-}
-
-// buMergesort - transliteration of Wikipedia's "Bottom up implementation with lists",
-// https://en.wikipedia.org/wiki/Merge_sort#Bottom-up_implementation_using_lists
-func buMergesort(head *Node) *Node {
-	if head == nil {
-		return nil
-	}
-	// Can pass 1-length lists to the rest of the function,
-	// because array[0] is 2^0 or 1 in size
-
-	var array [32]*Node
-	var result, next *Node
-	var i int
-
-	result = head
-
-	for result != nil {
-		next = result.Next
-		result.Next = nil
-
-		for i = 0; (i < 32) && (array[i] != nil); i++ {
-			result = merge(array[i], result)
-			array[i] = nil
-		}
-		if i == 32 {
-			i--
-		}
-		array[i] = result
-		result = next
+	fout, err := os.CreateTemp(dir, "sorted*.bin")
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer os.Remove(fout.Name())
 
-	result = nil
-	for i = 0; i < 32; i++ {
-		result = merge(array[i], result)
+	before := time.Now()
+	count, err := sorter.Merge(fout)
+	elapsed += time.Since(before)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	return result
-}
-
-func merge(p *Node, q *Node) *Node {
-	if p == nil {
-		return q
+	if err := fout.Close(); err != nil {
+		log.Fatal(err)
 	}
-	if q == nil {
-		return p
+	if count != n {
+		log.Printf("external sort of %d values output %d values\n", n, count)
+		os.Exit(2)
 	}
 
-	x := &q
-	if p.Data < q.Data {
-		x = &p
+	fin, err := os.Open(fout.Name())
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	for p != nil && q != nil {
-		n := &q
-		if p.Data < q.Data {
-			n = &p
+	defer fin.Close()
+	r := extsort.NewReader(fin)
+	var sz int
+	var prev uint
+	for ; ; sz++ {
+		value, err := r.ReadValue()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
 		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
+		if value < prev {
+			log.Printf("external sort of %d values not sorted at value %d\n", n, sz)
+			os.Exit(1)
+		}
+		prev = value
 	}
-
-	t.Next = p
-	if q != nil {
-		t.Next = q
+	if sz != n {
+		log.Printf("external sort of %d values read back %d values\n", n, sz)
+		os.Exit(2)
 	}
 
-	return h
-}
-
-// Print runs a linked list and prints its values on stdout
-func Print(list *Node) {
-	for node := list; node != nil; node = node.Next {
-		fmt.Printf("%d -> ", node.Data)
-	}
-	fmt.Println()
+	return elapsed, sorter.BytesWritten, sorter.BytesRead
 }
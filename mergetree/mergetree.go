@@ -0,0 +1,182 @@
+// Package mergetree records the splits and merges a mergesort does,
+// as a tree of ranges of original list positions, and writes
+// the tree as Graphviz DOT for rendering or as JSON for scripting.
+// A Recorder is an observed.Observer, so any of the listsort/observed
+// mergesorts can tell it their splits and merges.
+package mergetree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"mergesort/listsort"
+	"mergesort/listsort/observed"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+// Range is a sublist the mergesort split or merged: the nodes
+// originally at positions Lo up to but not including Hi.
+// Split and Merge are the operation numbers, counting from 1,
+// of splitting the Range, and of merging its Children into it.
+// Either can be 0 if the mergesort didn't do that operation.
+// One-node Ranges have neither. Children are in the order the
+// merge took them, two of them except for the k-way mergesort.
+type Range struct {
+	Lo       int      `json:"lo"`
+	Hi       int      `json:"hi"`
+	Size     int      `json:"size"`
+	Split    int      `json:"split,omitempty"`
+	Merge    int      `json:"merge,omitempty"`
+	Children []*Range `json:"children,omitempty"`
+}
+
+// Tree is the splits and merges of one mergesort of one list.
+type Tree struct {
+	Algorithm  string `json:"algorithm"`
+	Size       int    `json:"size"`
+	Operations int    `json:"operations"`
+	Root       *Range `json:"root"`
+}
+
+// Recorder builds a Tree as a mergesort tells it about
+// the splits and merges it does. It ignores the other events.
+type Recorder struct {
+	observed.NopObserver
+	algorithm string
+	size      int
+	positions map[*Node]int
+	ranges    map[[2]int]*Range
+	ops       int
+	last      *Range
+}
+
+// NewRecorder starts recording a mergesort of the list at head,
+// which must not have been sorted yet, so that its nodes'
+// original positions are known.
+func NewRecorder(algorithm string, head *Node) *Recorder {
+	positions := listsort.Positions(head)
+	return &Recorder{
+		algorithm: algorithm,
+		size:      len(positions),
+		positions: positions,
+		ranges:    make(map[[2]int]*Range),
+	}
+}
+
+// Split records dividing the size nodes starting at head
+// into sublists. The Merge of the sublists links them to it.
+func (r *Recorder) Split(head *Node, size int) {
+	r.ops++
+	r.find(head, size).Split = r.ops
+}
+
+// Merge records merging sorted lists, lists[i] holding sizes[i]
+// nodes. The lists don't have to be nil-terminated, Merge only
+// looks at sizes[i] nodes of each.
+func (r *Recorder) Merge(lists []*Node, sizes []int) {
+	r.ops++
+	children := make([]*Range, len(lists))
+	lo, hi, size := r.size, 0, 0
+	for i, list := range lists {
+		children[i] = r.find(list, sizes[i])
+		lo, hi = min(lo, children[i].Lo), max(hi, children[i].Hi)
+		size += sizes[i]
+	}
+	merged := r.get(lo, hi, size)
+	merged.Merge = r.ops
+	merged.Children = children
+	r.last = merged
+}
+
+// Tree returns the recorded splits and merges. Its root is
+// the Range of the last merge.
+func (r *Recorder) Tree() *Tree {
+	root := r.last
+	if root == nil && r.size > 0 {
+		root = r.get(0, r.size, r.size)
+	}
+	return &Tree{
+		Algorithm:  r.algorithm,
+		Size:       r.size,
+		Operations: r.ops,
+		Root:       root,
+	}
+}
+
+// find returns the Range holding the size nodes starting at head,
+// from the lowest to the highest of their original positions.
+func (r *Recorder) find(head *Node, size int) *Range {
+	lo, hi := r.size, 0
+	for node, i := head, 0; i < size; node, i = node.Next, i+1 {
+		pos := r.positions[node]
+		lo, hi = min(lo, pos), max(hi, pos+1)
+	}
+	return r.get(lo, hi, size)
+}
+
+func (r *Recorder) get(lo, hi, size int) *Range {
+	key := [2]int{lo, hi}
+	rng, ok := r.ranges[key]
+	if !ok {
+		rng = &Range{Lo: lo, Hi: hi, Size: size}
+		r.ranges[key] = rng
+	}
+	return rng
+}
+
+// WriteJSON writes the tree as an indented JSON object,
+// with each Range's Children nested inside it.
+func (t *Tree) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// WriteDOT writes the tree as a Graphviz digraph, the whole list
+// at the top, an edge from each Range to each of its Children.
+// Labels show each Range's positions, size and operation numbers.
+func (t *Tree) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "digraph mergetree {\n\tlabel=\"%s mergesort, %d nodes, %d operations\";\n\tnode [shape=box];\n",
+		t.Algorithm, t.Size, t.Operations); err != nil {
+		return err
+	}
+	if t.Root != nil {
+		if err := writeDOT(w, t.Root); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "}\n")
+	return err
+}
+
+func writeDOT(w io.Writer, rng *Range) error {
+	label := fmt.Sprintf("[%d,%d)", rng.Lo, rng.Hi)
+	if rng.Size > 1 {
+		label += fmt.Sprintf("\\n%d nodes", rng.Size)
+	}
+	if rng.Split > 0 {
+		label += fmt.Sprintf("\\nsplit %d", rng.Split)
+	}
+	if rng.Merge > 0 {
+		label += fmt.Sprintf("\\nmerge %d", rng.Merge)
+	}
+	if _, err := fmt.Fprintf(w, "\t%s [label=\"%s\"];\n", dotID(rng), label); err != nil {
+		return err
+	}
+	for _, child := range rng.Children {
+		if _, err := fmt.Fprintf(w, "\t%s -> %s;\n", dotID(rng), dotID(child)); err != nil {
+			return err
+		}
+		if err := writeDOT(w, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dotID(rng *Range) string {
+	return fmt.Sprintf("r%d_%d", rng.Lo, rng.Hi)
+}
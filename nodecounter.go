@@ -0,0 +1,169 @@
+package main
+
+/*
+ * Count node reads, .Next pointer writes and split-walk steps
+ * along with comparisons, for any of the listsort mergesorts
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unsafe"
+
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/listsort/observed"
+	"mergesort/prng"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+// counts of the work one sort does. A read is a load of a node's
+// .Data or .Next field, a write is a store to a node's .Next field,
+// a step is moving one node along the list while looking for
+// where to split it, or where a run ends. Every step is also a read.
+type counts struct {
+	comparisons, reads, writes, steps int
+}
+
+// counter is the observed.Observer that counts a sort's work
+type counter struct {
+	observed.NopObserver
+	counts
+}
+
+func (c *counter) Read(*Node)         { c.reads++ }
+func (c *counter) Write(*Node)        { c.writes++ }
+func (c *counter) Compare(_, _ *Node) { c.comparisons++ }
+func (c *counter) Step(*Node)         { c.steps++ }
+
+func main() {
+	algorithms := flag.String("a", "all", "comma separated algorithms to count, or all: "+strings.Join(observed.Names, ", "))
+	minGallop := flag.Int("W", 7, "galloping merges gallop after this many wins in a row")
+	parallelDepth := flag.Int("d", 3, "parallel mergesort recursion depth using goroutines")
+	k := flag.Int("k", 4, "number of sublists the k-way mergesort merges")
+
+	alreadySorted := flag.Bool("s", false, "already sorted low-to-high list")
+	reverseSorted := flag.Bool("S", false, "reverse sorted high-to-low list")
+	distribution := flag.String("v", "", "data value distribution: "+listgen.Distributions)
+
+	countIncrement := flag.Int("i", 200000, "increment of list size")
+	countBegin := flag.Int("b", 1000, "beginning list size")
+	countUntil := flag.Int("u", 18000000, "sort lists up to this size")
+
+	iterations := flag.Int("I", 10, "number of sorts conducted at any given list length")
+
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
+	flag.Parse()
+
+	names, err := observed.Select(*algorithms)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := observed.Options{MinGallop: *minGallop, Depth: *parallelDepth, K: *k}
+	sorts := make([]func(*Node, observed.Observer) *Node, len(names))
+	for i, name := range names {
+		if sorts[i], err = observed.Lookup(name, opts); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
+
+	hostname, _ := os.Hostname() // not going to fail
+
+	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
+	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
+		*countBegin, *countUntil, *countIncrement)
+	fmt.Printf("# %d iterations of a given list length\n", *iterations)
+
+	fmt.Print("# idiomatic list in-memory ordering\n")
+	fmt.Printf("# %s random numbers as list node values\n", *prngName)
+	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
+
+	var listCreation func(int, bool) *Node
+	listCreation = listgen.RandomValueList
+	listCreationPhrase := "randomly chosen data"
+	if *alreadySorted {
+		listCreation = listgen.PresortedList
+		listCreationPhrase = "presorted"
+	}
+	if *reverseSorted {
+		listCreation = listgen.ReverseSortedList
+		listCreationPhrase = "reverse sorted"
+	}
+	if *distribution != "" {
+		if *alreadySorted || *reverseSorted {
+			log.Fatalf("-v doesn't allow -s or -S\n")
+		}
+		var err error
+		if listCreation, listCreationPhrase, err = listgen.Distribution(*distribution); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Printf("# %s data values\n", listCreationPhrase)
+	fmt.Printf("# galloping merges gallop after %d wins in a row, parallel depth %d, k-way k %d\n",
+		*minGallop, *parallelDepth, *k)
+
+	fmt.Printf("# size, then comparisons, node reads, .Next writes, steps for each of %s\n",
+		strings.Join(names, ", "))
+
+	for n := *countBegin; n < *countUntil; n += *countIncrement {
+
+		totals := make([]counts, len(sorts))
+
+		for j := 0; j < *iterations; j++ {
+			head := listCreation(n, true)
+			// original order of nodes restorable
+			order := listgen.NodeOrder(head)
+
+			for i, sort := range sorts {
+				c := &counter{}
+				nl := sort(head, c)
+				checkSorted(nl, n, names[i])
+				totals[i].comparisons += c.comparisons
+				totals[i].reads += c.reads
+				totals[i].writes += c.writes
+				totals[i].steps += c.steps
+				head = listgen.ResetList(order)
+			}
+		}
+
+		fmt.Printf("%d", n)
+		for _, t := range totals {
+			fmt.Printf("\t%d\t%d\t%d\t%d",
+				t.comparisons / *iterations,
+				t.reads / *iterations,
+				t.writes / *iterations,
+				t.steps / *iterations,
+			)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+}
+
+func checkSorted(head *Node, nominalSize int, phrase string) {
+	if sz, sorted := listsort.IsSorted(head); !sorted {
+		log.Printf("list of size %d not sorted at element %d, %s\n", nominalSize, sz, phrase)
+		os.Exit(1)
+	} else if sz != nominalSize {
+		log.Printf("list of size %d had %d elements after %s sort\n", nominalSize, sz, phrase)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,95 @@
+// Package prng creates the named pseudo-random number generators
+// that the benchmark programs choose list data values with.
+// Every generator but "crypto" produces the same numbers
+// every time it gets the same seed, so that a run
+// can be replayed exactly by giving it the same seed.
+package prng
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// Names lists the generators New knows, for command line help.
+const Names = "pcg, chacha8, xorshift, crypto"
+
+// New returns the generator called name, seeded with seed.
+//
+//   - pcg is math/rand/v2's PCG, a 128-bit permuted congruential generator
+//   - chacha8 is math/rand/v2's ChaCha8, a cryptographically strong generator
+//   - xorshift is Marsaglia's xorshift64*, the cheapest of them
+//   - crypto reads crypto/rand, and ignores seed: runs using it can't be replayed
+func New(name string, seed uint64) (*rand.Rand, error) {
+	var src rand.Source
+	switch name {
+	case "pcg":
+		src = rand.NewPCG(seed, splitmix64(seed))
+	case "chacha8":
+		var key [32]byte
+		x := seed
+		for i := 0; i < len(key); i += 8 {
+			x = splitmix64(x)
+			binary.LittleEndian.PutUint64(key[i:], x)
+		}
+		src = rand.NewChaCha8(key)
+	case "xorshift":
+		src = newXorshift(seed)
+	case "crypto":
+		src = cryptoSource{}
+	default:
+		return nil, fmt.Errorf("unknown PRNG %q, want one of %s", name, Names)
+	}
+	return rand.New(src), nil
+}
+
+// Seed returns a seed made from the time and the process ID,
+// for runs that don't get an explicit seed.
+func Seed() uint64 {
+	return uint64(time.Now().UnixNano()) ^ uint64(os.Getpid())<<32
+}
+
+// splitmix64 scrambles x, so that similar seeds,
+// 1, 2, 3..., don't give generators similar states.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// xorshift is Marsaglia's xorshift64* generator.
+type xorshift struct {
+	state uint64
+}
+
+func newXorshift(seed uint64) *xorshift {
+	state := splitmix64(seed)
+	if state == 0 {
+		// xorshift state can't be zero, it would stay zero
+		state = 1
+	}
+	return &xorshift{state: state}
+}
+
+// Uint64 makes xorshift a math/rand/v2 Source.
+func (x *xorshift) Uint64() uint64 {
+	x.state ^= x.state >> 12
+	x.state ^= x.state << 25
+	x.state ^= x.state >> 27
+	return x.state * 0x2545f4914f6cdd1d
+}
+
+// cryptoSource is a math/rand/v2 Source that reads crypto/rand.
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
@@ -7,9 +7,9 @@ import (
 	"log"
 	"math"
 	"math/big"
-	"math/rand"
-	"os"
-	"time"
+	"math/rand/v2"
+
+	"mergesort/prng"
 )
 
 // Node is an element of a linked list
@@ -22,15 +22,24 @@ func main() {
 	useCryptoRand := flag.Bool("c", false, "use cryptographic PRNG")
 	composeSequential := flag.Bool("s", false, "compose a sequential list")
 	n := flag.Int("n", 99, "number of integer-value nodes in list")
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano() | int64(os.Getpid()))
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	var err error
+	if rng, err = prng.New(*prngName, *seed); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
 
 	var head *Node
 	if *composeSequential {
 		head = sequentialValueList(*n)
 	} else {
-		head = randomValueList(*n, *useCryptoRand)
+		head = randomValueList(*n, !*useCryptoRand)
 	}
 	Print(head)
 	fmt.Println()
@@ -56,6 +65,9 @@ func listSize(head *Node) int {
 
 var maxInt = big.NewInt(math.MaxInt32)
 
+// rng chooses the random data values of cheaply random lists.
+var rng *rand.Rand
+
 func randomValueList(n int, useCheapRand bool) *Node {
 
 	var head *Node
@@ -63,7 +75,7 @@ func randomValueList(n int, useCheapRand bool) *Node {
 	for i := 0; i < n; i++ {
 		var ri int
 		if useCheapRand {
-			ri = rand.Int()
+			ri = rng.Int()
 		} else {
 			mp, err := crand.Int(crand.Reader, maxInt)
 			if err != nil {
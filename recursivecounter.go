@@ -0,0 +1,645 @@
+package main
+
+/*
+ * Count sorting comparisons of the recursivetest mergesort variants
+ */
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unsafe"
+
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/listsort/generic"
+	"mergesort/prng"
+)
+
+// Node is an element of a linked list
+type Node = listsort.Node
+
+// variant is one of the recursivetest mergesorts, chosen
+// by the same command line flag as in recursivetest.
+type variant struct {
+	chosen *bool
+	name   string
+	sort   func(head *Node, size int) *Node
+	count  int
+}
+
+// comparisons counts data value comparisons, every variant's
+// sort function adds to it by calling compare.
+var comparisons int
+
+func main() {
+	variants := []*variant{
+		{flag.Bool("r", false, "count purely recursive mergesort"), "recursive",
+			func(head *Node, _ int) *Node { return generic.RecursiveMergeSort(head, compare) }, 0},
+		{flag.Bool("z", false, "count purely recursive mergesort with user stack"), "user stack",
+			func(head *Node, _ int) *Node { return generic.OwnstackMergeSort(head, compare) }, 0},
+		{flag.Bool("a", false, "count purely recursive alternating mergesort"), "alternating",
+			func(head *Node, _ int) *Node { return recursiveMergeSortLeft(head) }, 0},
+		{flag.Bool("d", false, "count purely recursive mergesort, rhs first"), "rhs first",
+			func(head *Node, _ int) *Node { return recursiveMergeSortRHS(head) }, 0},
+		{flag.Bool("e", false, "count counted purely recursive mergesort"), "counted",
+			countedRecursiveMergeSort, 0},
+		{flag.Bool("f", false, "count recursive mergesort with merge function"), "merge function",
+			func(head *Node, _ int) *Node { return recursiveMergeSortMerge(head) }, 0},
+		{flag.Bool("o", false, "count recursive mergesort with user stack 2"), "user stack 2",
+			func(head *Node, _ int) *Node { return ownstackMergeSort2(head) }, 0},
+		{flag.Bool("p", false, "count recursive mergesort with user stack 3"), "user stack 3",
+			func(head *Node, _ int) *Node { return ownstackMergeSort3(head) }, 0},
+	}
+	alreadySorted := flag.Bool("s", false, "already sorted low-to-high list")
+	reverseSorted := flag.Bool("S", false, "reverse sorted high-to-low list")
+	distribution := flag.String("v", "", "data value distribution: "+listgen.Distributions)
+
+	countIncrement := flag.Int("i", 200000, "increment of list size")
+	countBegin := flag.Int("b", 1000, "beginning list size")
+	countUntil := flag.Int("u", 18000000, "sort lists up to this size")
+
+	iterations := flag.Int("I", 10, "number of sorts conducted at any given list length")
+
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
+	flag.Parse()
+
+	// no variant flags counts all of them
+	var counted []*variant
+	for _, v := range variants {
+		if *v.chosen {
+			counted = append(counted, v)
+		}
+	}
+	if len(counted) == 0 {
+		counted = variants
+	}
+
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
+
+	hostname, _ := os.Hostname() // not going to fail
+
+	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
+	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
+		*countBegin, *countUntil, *countIncrement)
+	fmt.Printf("# %d iterations of a given list length\n", *iterations)
+
+	fmt.Print("# idiomatic list in-memory ordering\n")
+	fmt.Printf("# %s random numbers as list node values\n", *prngName)
+	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
+
+	var listCreation func(int, bool) *Node
+	listCreation = listgen.RandomValueList
+	listCreationPhrase := "randomly chosen data"
+	if *alreadySorted {
+		listCreation = listgen.PresortedList
+		listCreationPhrase = "presorted"
+	}
+	if *reverseSorted {
+		listCreation = listgen.ReverseSortedList
+		listCreationPhrase = "reverse sorted"
+	}
+	if *distribution != "" {
+		if *alreadySorted || *reverseSorted {
+			log.Fatalf("-v doesn't allow -s or -S\n")
+		}
+		var err error
+		if listCreation, listCreationPhrase, err = listgen.Distribution(*distribution); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Printf("# %s data values\n", listCreationPhrase)
+
+	columns := []string{"size"}
+	for _, v := range counted {
+		columns = append(columns, v.name)
+	}
+	fmt.Printf("# %s\n", strings.Join(columns, ", "))
+
+	for n := *countBegin; n < *countUntil; n += *countIncrement {
+
+		for _, v := range counted {
+			v.count = 0
+		}
+
+		for j := 0; j < *iterations; j++ {
+			head := listCreation(n, true)
+			// original order of nodes restorable
+			order := listgen.NodeOrder(head)
+
+			for _, v := range counted {
+				comparisons = 0
+				nl := v.sort(head, n)
+				v.count += comparisons
+				checkSorted(nl, n, v.name)
+				head = listgen.ResetList(order)
+			}
+		}
+
+		fmt.Printf("%d", n)
+		for _, v := range counted {
+			fmt.Printf("\t%d", v.count / *iterations)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+}
+
+func checkSorted(head *Node, nominalSize int, phrase string) {
+	if sz, sorted := listsort.IsSorted(head); !sorted {
+		log.Printf("list of size %d not sorted at element %d, %s\n", nominalSize, sz, phrase)
+		os.Exit(1)
+	} else if sz != nominalSize {
+		log.Printf("list of size %d had %d elements after %s sort\n", nominalSize, sz, phrase)
+		os.Exit(2)
+	}
+}
+
+// compare is cmp.Compare, counting every comparison
+func compare(a, b uint) int {
+	comparisons++
+	return cmp.Compare(a, b)
+}
+
+// merge is listsort.Merge, counting every comparison
+func merge(p, q *Node) *Node {
+	return generic.Merge(p, q, compare)
+}
+
+// The rest are recursivetest's variants, comparing data values
+// with compare instead of <=, and merging with merge instead of
+// listsort.Merge, so they do the same work recursivetest times.
+
+func recursiveMergeSortMerge(head *Node) *Node {
+	if head.Next == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	// Setting rabbit and turtle like this means we split an
+	// odd-length-list (head) into lists of length n (right)
+	// and n+1 (left).
+	rabbit, turtle := head.Next, &head
+
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+
+	right := *turtle
+	*turtle = nil // nil-terminates left side sublist
+
+	left := recursiveMergeSortMerge(head)
+	right = recursiveMergeSortMerge(right)
+
+	return merge(left, right)
+}
+
+// countedRecursiveMergeSort same as func recursiveMergeSort,
+// except it only touches half the list nodes to find
+// the middle of the list.
+func countedRecursiveMergeSort(head *Node, size int) *Node {
+	if head.Next == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	p := &head
+	var i, leftSize int
+
+	for i = 0; i < size; i += 2 {
+		p = &((*p).Next)
+		leftSize++
+	}
+
+	right := *p
+	*p = nil
+
+	left := countedRecursiveMergeSort(head, leftSize)
+	right = countedRecursiveMergeSort(right, size-leftSize)
+
+	// Set h, t variables so that the loop doing the merge
+	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
+	x := &right
+	if compare(left.Data, right.Data) <= 0 {
+		x = &left
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	// left and right are either equal in length, or right is one
+	// node longer, but the "<=" check might take more from one list
+	// than the other. Have to check both for nil.
+	for left != nil && right != nil {
+		n := &right
+		if compare(left.Data, right.Data) <= 0 {
+			n = &left
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+		// At the end of this for-loop, t.Next ends up being nil
+		// because of the left/right list splitting.
+	}
+
+	// Either left or right are nil. If left == nil,
+	// assigning nil to t.Next is no issue.
+	t.Next = left
+	if right != nil {
+		// but if right is nil, can't assign nil to t.Next,
+		// because left was non-nil.
+		t.Next = right
+	}
+
+	return h
+}
+
+func recursiveMergeSortRHS(head *Node) *Node {
+	if head.Next == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	// Setting rabbit and turtle like this means we split an
+	// odd-length-list (head) into lists of length n (right)
+	// and n+1 (left).
+	rabbit, turtle := head.Next, &head
+
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+
+	right := *turtle
+	*turtle = nil
+
+	// sort the right-hand half first
+	right = recursiveMergeSortRHS(right)
+	left := recursiveMergeSortRHS(head)
+
+	// Set h, t variables so that the loop doing the merge
+	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
+	x := &right
+	if compare(left.Data, right.Data) <= 0 {
+		x = &left
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	// left and right are either equal in length, or right is one
+	// node longer, but the "<=" check might take more from one list
+	// than the other. Have to check both for nil.
+	for left != nil && right != nil {
+		n := &right
+		if compare(left.Data, right.Data) <= 0 {
+			n = &left
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+		// At the end of this for-loop, t.Next ends up being nil
+		// because of the left/right list splitting.
+	}
+
+	// Either left or right are nil. If left == nil,
+	// assigning nil to t.Next is no issue.
+	t.Next = left
+	if right != nil {
+		// but if right is nil, can't assign nil to t.Next,
+		// because left was non-nil.
+		t.Next = right
+	}
+
+	return h
+}
+
+type stackFrame2 struct {
+	formalArgument *Node
+	left           *Node
+	right          *Node
+	leftSorted     *Node
+	next           *stackFrame2
+}
+
+// ownstackMergeSort2 - a "recursive" mergesort that's all user level.
+// There's no implicit function call stack, it's explicit. The "stack frame"
+// is struct stackFrame2. Further, both "stack" and "stack frames" are
+// allocated on the call stack of func ownstackMergeSort2. I think there's
+// no heap allocations in this function.
+func ownstackMergeSort2(head *Node) *Node {
+
+	// recursivetest never sorts 1-node lists, which this
+	// "recursion" can't return from
+	if head == nil || head.Next == nil {
+		return head
+	}
+
+	var stack [32]stackFrame2
+	var ply int
+	var returnValue *Node
+
+	stack[ply].formalArgument = head
+
+	for {
+		if stack[ply].formalArgument.Next == nil && stack[ply].left == nil {
+			// "recursion" has bottomed out
+			// fmt.Printf("1-length list, recursion bottomed-out\n")
+			returnValue = stack[ply].formalArgument
+			// .left, .right, .leftSorted should all contain nil
+			ply--
+			continue // "return" from bottomed-out recursion
+		}
+
+		if stack[ply].leftSorted == nil {
+			if stack[ply].left == nil {
+				// haven't recursed on either .left or .right
+				stack[ply].left, stack[ply].right = listsort.Split(stack[ply].formalArgument)
+				// set up stack frame for mergesort(left)
+				tmp := stack[ply].left
+				ply++ // stack[ply] is a new frame
+				stack[ply].formalArgument = tmp
+				continue // "call" mergesort(left)
+			}
+			// returned from mergesort(left), returnValue should not contain nil
+			stack[ply].leftSorted = returnValue
+			returnValue = nil
+			// set up stack frame for mergesort(right)
+			tmp := stack[ply].right
+			ply++
+			stack[ply].formalArgument = tmp
+			continue // "call" mergesort(right)
+		}
+
+		// stack[ply].leftSorted != nil, "return" from mergesort(right)
+
+		returnValue = merge(stack[ply].leftSorted, returnValue)
+		stack[ply].leftSorted = nil
+		stack[ply].left = nil
+		stack[ply].right = nil
+		ply--
+		if ply < 0 {
+			break
+		}
+		// returnValue is non-nil, the merge of .left and .right
+	}
+
+	return returnValue
+}
+
+func ownstackMergeSort3(head *Node) *Node {
+
+	// recursivetest never sorts 1-node lists, which this
+	// "recursion" can't return from
+	if head == nil || head.Next == nil {
+		return head
+	}
+
+	var frames *stackFrame2
+
+	for i := 0; i < 32; i++ {
+		frame := new(stackFrame2)
+		frame.next = frames
+		frames = frame
+	}
+
+	return realOwnstackMergeSort3(head, frames)
+}
+
+func realOwnstackMergeSort3(head *Node, frames *stackFrame2) *Node {
+
+	var stack *stackFrame2
+	var returnValue *Node
+
+	frame := frames
+	frames = frames.next
+
+	frame.formalArgument = head
+
+	frame.next = stack
+	stack = frame
+
+	for {
+
+		frame = stack
+		stack = stack.next
+
+		if frame.formalArgument.Next == nil && frame.left == nil {
+			// "recursion" has bottomed out
+			// fmt.Printf("1-length list, recursion bottomed-out\n")
+			returnValue = frame.formalArgument
+			// .left, .right, .leftSorted should all contain nil
+			frame.next = frames
+			frames = frame
+			continue // "return" from bottomed-out recursion
+		}
+
+		if frame.leftSorted == nil {
+			if frame.left == nil {
+				// haven't recursed on either .left or .right
+				frame.left, frame.right = listsort.Split(frame.formalArgument)
+				// set up stack frame for mergesort(left)
+				newframe := frames
+				frames = frames.next
+				newframe.formalArgument = frame.left
+				frame.next = stack
+				stack = frame
+				newframe.next = stack
+				stack = newframe
+				continue // "call" mergesort(left)
+			}
+			// returned from mergesort(left), returnValue should not contain nil
+			frame.leftSorted = returnValue
+			returnValue = nil
+			// set up stack frame for mergesort(right)
+			newframe := frames
+			frames = frames.next
+			newframe.formalArgument = frame.right
+			frame.next = stack
+			stack = frame
+			newframe.next = stack
+			stack = newframe
+			continue // "call" mergesort(right)
+		}
+
+		// frame.leftSorted != nil, "return" from mergesort(right)
+
+		returnValue = merge(frame.leftSorted, returnValue)
+		frame.leftSorted = nil
+		frame.left = nil
+		frame.right = nil
+		frame.next = frames
+		frames = frame
+		if stack == nil {
+			break
+		}
+		// returnValue is non-nil, the merge of .left and .right
+	}
+
+	return returnValue
+}
+
+func recursiveMergeSortLeft(head *Node) *Node {
+	if head.Next == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	// Setting rabbit and turtle like this means we split an
+	// odd-length-list (head) into lists of length n (right)
+	// and n+1 (left).
+	rabbit, turtle := head.Next, &head
+
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+
+	right := *turtle
+	*turtle = nil
+
+	// sort the right-hand half first
+	right = recursiveMergeSortRight(right)
+	left := recursiveMergeSortRight(head)
+
+	// Set h, t variables so that the loop doing the merge
+	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
+	x := &right
+	if compare(left.Data, right.Data) <= 0 {
+		x = &left
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	// left and right are either equal in length, or right is one
+	// node longer, but the "<=" check might take more from one list
+	// than the other. Have to check both for nil.
+	for left != nil && right != nil {
+		n := &right
+		if compare(left.Data, right.Data) <= 0 {
+			n = &left
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+		// At the end of this for-loop, t.Next ends up being nil
+		// because of the left/right list splitting.
+	}
+
+	// Either left or right are nil. If left == nil,
+	// assigning nil to t.Next is no issue.
+	t.Next = left
+	if right != nil {
+		// but if right is nil, can't assign nil to t.Next,
+		// because left was non-nil.
+		t.Next = right
+	}
+
+	return h
+}
+
+func recursiveMergeSortRight(head *Node) *Node {
+	if head.Next == nil {
+		// single node list is sorted by definition
+		return head
+	}
+
+	// because of recursion bottoming out at a 1-long-list,
+	// head points to a list of at least 2 elements.
+
+	// Setting rabbit and turtle like this means we split an
+	// odd-length-list (head) into lists of length n (right)
+	// and n+1 (left).
+	rabbit, turtle := head.Next, &head
+
+	for rabbit != nil {
+		turtle = &(*turtle).Next
+		if rabbit = rabbit.Next; rabbit != nil {
+			rabbit = rabbit.Next
+		}
+	}
+
+	right := *turtle
+	*turtle = nil
+
+	left := recursiveMergeSortLeft(head)
+	right = recursiveMergeSortLeft(right)
+
+	// Set h, t variables so that the loop doing the merge
+	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
+	x := &right
+	if compare(left.Data, right.Data) <= 0 {
+		x = &left
+	}
+
+	h, t := *x, *x
+	*x = (*x).Next
+
+	// left and right are either equal in length, or right is one
+	// node longer, but the "<=" check might take more from one list
+	// than the other. Have to check both for nil.
+	for left != nil && right != nil {
+		n := &right
+		if compare(left.Data, right.Data) <= 0 {
+			n = &left
+		}
+		t.Next = *n
+		*n = (*n).Next
+		t = t.Next
+		// At the end of this for-loop, t.Next ends up being nil
+		// because of the left/right list splitting.
+	}
+
+	// Either left or right are nil. If left == nil,
+	// assigning nil to t.Next is no issue.
+	t.Next = left
+	if right != nil {
+		// but if right is nil, can't assign nil to t.Next,
+		// because left was non-nil.
+		t.Next = right
+	}
+
+	return h
+}
@@ -3,24 +3,21 @@ package main
 /* Recursive mergesort a few odd ways */
 
 import (
-	crand "crypto/rand"
 	"flag"
 	"fmt"
 	"log"
-	"math"
-	"math/big"
-	"math/rand"
 	"os"
 	"runtime"
 	"time"
 	"unsafe"
+
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/prng"
 )
 
 // Node is an element of a linked list
-type Node struct {
-	Data uint
-	Next *Node
-}
+type Node = listsort.Node
 
 func main() {
 	useCryptoRand := flag.Bool("c", false, "use cryptographic PRNG")
@@ -32,19 +29,31 @@ func main() {
 	useRecursiveSort6 := flag.Bool("f", false, "recursive mergesort with merge function")
 	useRecursiveSort7 := flag.Bool("o", false, "recursive mergesort with user stack 2")
 	useRecursiveSort8 := flag.Bool("p", false, "recursive mergesort with user stack 3")
+	useArena := flag.Bool("A", false, "allocate each list size's nodes from an arena, like mergetest.c -p")
 	reuseList := flag.Bool("R", false, "re-randomize and re-use list")
 	alreadySorted := flag.Bool("s", false, "already sorted low-to-high list")
 	reverseSorted := flag.Bool("S", false, "reverse sorted high-to-low list")
+	distribution := flag.String("v", "", "data value distribution: "+listgen.Distributions)
 	addressOrderedList := flag.Bool("m", false, "create address-ordered list for each sort")
 	garbageCollectAfter := flag.Bool("G", false, "collect garbage after each sort")
 	countIncrement := flag.Int("i", 200000, "increment of list size")
 	countBegin := flag.Int("b", 1000, "beginning list size")
 	countUntil := flag.Int("u", 18000000, "sort lists up to this size")
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano() | int64(os.Getpid()))
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
 	hostname, _ := os.Hostname() // not going to fail
 	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
 	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
 		*countBegin, *countUntil, *countIncrement)
 	sortType := "unknown"
@@ -71,13 +80,16 @@ func main() {
 		listType = "memory address"
 	}
 	fmt.Printf("# %s list in-memory ordering\n", listType)
+	if *useArena {
+		fmt.Println("# nodes allocated from an arena of slabs, reset after each sort")
+	}
 	if *reuseList {
 		fmt.Println("# re-random-value and re-use list")
 	}
 	if *garbageCollectAfter {
 		fmt.Println("# garbage collect after each sort iteration")
 	}
-	randomType := "math/rand"
+	randomType := *prngName
 	if *useCryptoRand {
 		randomType = "cryptographic"
 	}
@@ -85,29 +97,44 @@ func main() {
 	fmt.Printf("# nodes %d bytes in size\n", unsafe.Sizeof(Node{}))
 
 	var listCreation func(int, bool) *Node
-	listCreation = randomValueList
+	listCreation = listgen.RandomValueList
 	listCreationPhrase := "randomly chosen data"
 	if *addressOrderedList {
-		listCreation = memoryOrderedList
+		listCreation = listgen.MemoryOrderedList
 		listCreationPhrase = "unordered"
 		fmt.Printf("# node addresses ascending in memory\n")
 	}
 	if *alreadySorted {
-		listCreation = presortedList
+		listCreation = listgen.PresortedList
 		listCreationPhrase = "presorted"
 	}
 	if *reverseSorted {
-		listCreation = reverseSortedList
+		listCreation = listgen.ReverseSortedList
 		listCreationPhrase = "reverse sorted"
 	}
+	if *distribution != "" {
+		if *alreadySorted || *reverseSorted || *addressOrderedList {
+			log.Fatalf("-v doesn't allow -s, -S or -m\n")
+		}
+		var err error
+		if listCreation, listCreationPhrase, err = listgen.Distribution(*distribution); err != nil {
+			log.Fatal(err)
+		}
+	}
 	fmt.Printf("# %s data values\n", listCreationPhrase)
 
 	for n := *countBegin; n < *countUntil; n += *countIncrement {
 		var total time.Duration
 		var looping time.Duration
+		var arena *listgen.Arena
+		if *useArena {
+			// one slab big enough for the whole list, like perform_preallocation
+			arena = listgen.NewArena(n)
+			listgen.UseArena(arena)
+		}
 		var head *Node
 		if *reuseList {
-			head = listCreation(n, *useCryptoRand)
+			head = listCreation(n, !*useCryptoRand)
 		}
 		min := time.Duration((365 * 24 * 3600) * time.Second)
 		max := time.Duration(0)
@@ -115,16 +142,16 @@ func main() {
 			beforeIteration := time.Now()
 			if !*reuseList {
 				// fresh, new list every iteration
-				head = listCreation(n, *useCryptoRand)
+				head = listCreation(n, !*useCryptoRand)
 			}
 
 			var nl *Node
 			before := time.Now()
 			switch {
 			case *useRecursiveSort:
-				nl = recursiveMergeSort(head)
+				nl = listsort.RecursiveMergeSort(head)
 			case *useRecursiveSort2:
-				nl = ownstackMergeSort(head)
+				nl = listsort.OwnstackMergeSort(head)
 			case *useRecursiveSort3:
 				nl = recursiveMergeSortLeft(head)
 			case *useRecursiveSort4:
@@ -147,7 +174,7 @@ func main() {
 				min = elapsed
 			}
 
-			if sz, sorted := isSorted(nl); !sorted {
+			if sz, sorted := listsort.IsSorted(nl); !sorted {
 				log.Printf("list of size %d not sorted at element %d\n", n, sz)
 				os.Exit(1)
 			} else if sz != n {
@@ -156,7 +183,9 @@ func main() {
 			}
 
 			if *reuseList {
-				head = rerandomizeList(nl, *useCryptoRand)
+				head = listgen.RerandomizeList(nl, !*useCryptoRand)
+			} else if arena != nil {
+				arena.Reset()
 			}
 
 			if *garbageCollectAfter {
@@ -174,174 +203,6 @@ func main() {
 	fmt.Printf("# ending at %s on %s\n", time.Now().Format(time.RFC3339), hostname)
 }
 
-func isSorted(head *Node) (int, bool) {
-	if head == nil {
-		return 0, true
-	}
-	if head.Next == nil {
-		return 1, true
-	}
-	var sz int
-	for ; head.Next != nil; head = head.Next {
-		sz++
-		if head.Data > head.Next.Data {
-			return sz, false
-		}
-	}
-	sz++ // for-loop checks head.Next, count final element on list
-	return sz, true
-}
-
-var maxInt = big.NewInt(math.MaxInt32)
-
-func randomValueList(n int, useCheapRand bool) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		head = &Node{
-			Data: randomValue(useCheapRand),
-			Next: head,
-		}
-	}
-
-	return head
-}
-
-func presortedList(n int, _ bool) *Node {
-
-	var head *Node
-
-	for i := n - 1; i >= 0; i-- {
-		head = &Node{
-			Data: uint(i),
-			Next: head,
-		}
-	}
-
-	return head
-}
-
-func reverseSortedList(n int, _ bool) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		head = &Node{
-			Data: uint(i),
-			Next: head,
-		}
-	}
-
-	return head
-}
-
-func memoryOrderedList(n int, useCheapRand bool) *Node {
-
-	head := &Node{}
-	head.Data = uint(uintptr(unsafe.Pointer(head)))
-	tail := head
-
-	// Append new *Node to end of list - this will create
-	// a list that has blocks of nodes in descending address order
-	for i := 1; i < n; i++ {
-		nn := &Node{}
-		nn.Data = uint(uintptr(unsafe.Pointer(nn)))
-		tail.Next = nn
-		tail = tail.Next
-	}
-
-	// sort all nodes by address, so that even blocks of nodes are
-	// ordered by ascending address.
-	head = recursiveMergeSort(head)
-	return rerandomizeList(head, useCheapRand)
-}
-
-func rerandomizeList(head *Node, useCheapRand bool) *Node {
-	for node := head; node != nil; node = node.Next {
-		node.Data = randomValue(useCheapRand)
-	}
-	return head
-}
-
-func randomValue(useCheapRand bool) uint {
-	var ri int
-	if useCheapRand {
-		ri = rand.Int()
-	} else {
-		mp, err := crand.Int(crand.Reader, maxInt)
-		if err != nil {
-			log.Fatal(err)
-		}
-		ri = int(mp.Int64())
-	}
-	return uint(ri)
-}
-
-func recursiveMergeSort(head *Node) *Node {
-	if head.Next == nil {
-		// single node list is sorted by definition
-		return head
-	}
-
-	// because of recursion bottoming out at a 1-long-list,
-	// head points to a list of at least 2 elements.
-
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
-		}
-	}
-
-	right := *turtle
-	*turtle = nil
-
-	left := recursiveMergeSort(head)
-	right = recursiveMergeSort(right)
-
-	// Set h, t variables so that the loop doing the merge
-	// does not have to have a "if h == nil" check every iteration.
-	x := &right
-	if left.Data < right.Data {
-		x = &left
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
-	// than the other. Have to check both for nil.
-	for left != nil && right != nil {
-		n := &right
-		if left.Data < right.Data {
-			n = &left
-		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
-		// At the end of this for-loop, t.Next ends up being nil
-		// because of the left/right list splitting.
-	}
-
-	// Either left or right are nil. If left == nil,
-	// assigning nil to t.Next is no issue.
-	t.Next = left
-	if right != nil {
-		// but if right is nil, can't assign nil to t.Next,
-		// because left was non-nil.
-		t.Next = right
-	}
-
-	return h
-}
-
 func recursiveMergeSortMerge(head *Node) *Node {
 	if head.Next == nil {
 		// single node list is sorted by definition
@@ -369,7 +230,7 @@ func recursiveMergeSortMerge(head *Node) *Node {
 	left := recursiveMergeSortMerge(head)
 	right = recursiveMergeSortMerge(right)
 
-	return merge(left, right)
+	return listsort.Merge(left, right)
 }
 
 // countedRecursiveMergeSort same as func recursiveMergeSort,
@@ -400,8 +261,10 @@ func countedRecursiveMergeSort(head *Node, size int) *Node {
 
 	// Set h, t variables so that the loop doing the merge
 	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
 	x := &right
-	if left.Data < right.Data {
+	if left.Data <= right.Data {
 		x = &left
 	}
 
@@ -409,11 +272,11 @@ func countedRecursiveMergeSort(head *Node, size int) *Node {
 	*x = (*x).Next
 
 	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
+	// node longer, but the "<=" check might take more from one list
 	// than the other. Have to check both for nil.
 	for left != nil && right != nil {
 		n := &right
-		if left.Data < right.Data {
+		if left.Data <= right.Data {
 			n = &left
 		}
 		t.Next = *n
@@ -459,13 +322,16 @@ func recursiveMergeSortRHS(head *Node) *Node {
 	right := *turtle
 	*turtle = nil
 
-	left := recursiveMergeSortRHS(right)
-	right = recursiveMergeSortRHS(head)
+	// sort the right-hand half first
+	right = recursiveMergeSortRHS(right)
+	left := recursiveMergeSortRHS(head)
 
 	// Set h, t variables so that the loop doing the merge
 	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
 	x := &right
-	if left.Data < right.Data {
+	if left.Data <= right.Data {
 		x = &left
 	}
 
@@ -473,11 +339,11 @@ func recursiveMergeSortRHS(head *Node) *Node {
 	*x = (*x).Next
 
 	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
+	// node longer, but the "<=" check might take more from one list
 	// than the other. Have to check both for nil.
 	for left != nil && right != nil {
 		n := &right
-		if left.Data < right.Data {
+		if left.Data <= right.Data {
 			n = &left
 		}
 		t.Next = *n
@@ -499,88 +365,6 @@ func recursiveMergeSortRHS(head *Node) *Node {
 	return h
 }
 
-type stackFrame struct {
-	list   *Node
-	merged *Node
-	next   *stackFrame
-}
-
-func split(head *Node) (*Node, *Node) {
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
-		}
-	}
-	right := *turtle
-	*turtle = nil
-	return head, right
-}
-
-func ownstackMergeSort(head *Node) *Node {
-
-	stack := &stackFrame{
-		list: head,
-	}
-
-	var sorted *Node
-
-	for {
-		var elem *stackFrame
-
-		elem, stack = stack, stack.next
-
-		if elem.list == nil && stack == nil {
-			sorted = elem.merged
-			break
-		}
-
-		if elem.list != nil && elem.list.Next == nil {
-			// "recursion" has bottomed out at 1-node list
-			elem.merged, elem.list = elem.list, nil
-			elem.next, stack = stack, elem
-			continue
-		}
-
-		if elem.merged != nil {
-			// a merged sublist has "returned"
-
-			tmp := stack
-			stack = stack.next
-
-			if tmp.merged == nil {
-				elem.next, stack = stack, elem
-				tmp.next, stack = stack, tmp
-				continue
-			}
-
-			// both tmp and elem contain merged sublists
-
-			elem.merged = merge(elem.merged, tmp.merged)
-			elem.next, stack = stack, elem
-			// discarding tmp
-			continue
-		}
-
-		// still "recursing"
-		left, right := split(elem.list)
-		stack = &stackFrame{
-			list: left,
-			next: stack,
-		}
-		stack = &stackFrame{
-			list: right,
-			next: stack,
-		}
-	}
-
-	return sorted
-}
-
 type stackFrame2 struct {
 	formalArgument *Node
 	left           *Node
@@ -619,7 +403,7 @@ func ownstackMergeSort2(head *Node) *Node {
 		if stack[ply].leftSorted == nil {
 			if stack[ply].left == nil {
 				// haven't recursed on either .left or .right
-				stack[ply].left, stack[ply].right = split(stack[ply].formalArgument)
+				stack[ply].left, stack[ply].right = listsort.Split(stack[ply].formalArgument)
 				// set up stack frame for mergesort(left)
 				tmp := stack[ply].left
 				ply++ // stack[ply] is a new frame
@@ -638,7 +422,7 @@ func ownstackMergeSort2(head *Node) *Node {
 
 		// stack[ply].leftSorted != nil, "return" from mergesort(right)
 
-		returnValue = merge(stack[ply].leftSorted, returnValue)
+		returnValue = listsort.Merge(stack[ply].leftSorted, returnValue)
 		stack[ply].leftSorted = nil
 		stack[ply].left = nil
 		stack[ply].right = nil
@@ -700,7 +484,7 @@ func realOwnstackMergeSort3(head *Node, frames *stackFrame2) *Node {
 		if frame.leftSorted == nil {
 			if frame.left == nil {
 				// haven't recursed on either .left or .right
-				frame.left, frame.right = split(frame.formalArgument)
+				frame.left, frame.right = listsort.Split(frame.formalArgument)
 				// set up stack frame for mergesort(left)
 				newframe := frames
 				frames = frames.next
@@ -727,7 +511,7 @@ func realOwnstackMergeSort3(head *Node, frames *stackFrame2) *Node {
 
 		// frame.leftSorted != nil, "return" from mergesort(right)
 
-		returnValue = merge(frame.leftSorted, returnValue)
+		returnValue = listsort.Merge(frame.leftSorted, returnValue)
 		frame.leftSorted = nil
 		frame.left = nil
 		frame.right = nil
@@ -742,85 +526,6 @@ func realOwnstackMergeSort3(head *Node, frames *stackFrame2) *Node {
 	return returnValue
 }
 
-// buMergesort - transliteration of Wikipedia's "Bottom up implementation with lists",
-// https://en.wikipedia.org/wiki/Merge_sort#Bottom-up_implementation_using_lists
-func buMergesort(head *Node) *Node {
-	if head == nil {
-		return nil
-	}
-	// Can pass 1-length lists to the rest of the function,
-	// because array[0] is 2^0 or 1 in size
-
-	var array [32]*Node
-	var result, next *Node
-	var i int
-
-	result = head
-
-	for result != nil {
-		next = result.Next
-		result.Next = nil
-
-		for i = 0; (i < 32) && (array[i] != nil); i++ {
-			result = merge(array[i], result)
-			array[i] = nil
-		}
-		if i == 32 {
-			i--
-		}
-		array[i] = result
-		result = next
-	}
-
-	result = nil
-	for i = 0; i < 32; i++ {
-		result = merge(array[i], result)
-	}
-
-	return result
-}
-
-func merge(p *Node, q *Node) *Node {
-	if p == nil {
-		return q
-	}
-	if q == nil {
-		return p
-	}
-
-	x := &q
-	if p.Data < q.Data {
-		x = &p
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	for p != nil && q != nil {
-		n := &q
-		if p.Data < q.Data {
-			n = &p
-		}
-		t.Next = *n
-		*n = (*n).Next
-		t = t.Next
-	}
-
-	t.Next = p
-	if q != nil {
-		t.Next = q
-	}
-
-	return h
-}
-
-// Print runs a linked list and prints its values on stdout
-func Print(list *Node) {
-	for node := list; node != nil; node = node.Next {
-		fmt.Printf("%d -> ", node.Data)
-	}
-	fmt.Println()
-}
 func recursiveMergeSortLeft(head *Node) *Node {
 	if head.Next == nil {
 		// single node list is sorted by definition
@@ -845,13 +550,16 @@ func recursiveMergeSortLeft(head *Node) *Node {
 	right := *turtle
 	*turtle = nil
 
-	left := recursiveMergeSortRight(right)
-	right = recursiveMergeSortRight(head)
+	// sort the right-hand half first
+	right = recursiveMergeSortRight(right)
+	left := recursiveMergeSortRight(head)
 
 	// Set h, t variables so that the loop doing the merge
 	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
 	x := &right
-	if left.Data < right.Data {
+	if left.Data <= right.Data {
 		x = &left
 	}
 
@@ -859,11 +567,11 @@ func recursiveMergeSortLeft(head *Node) *Node {
 	*x = (*x).Next
 
 	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
+	// node longer, but the "<=" check might take more from one list
 	// than the other. Have to check both for nil.
 	for left != nil && right != nil {
 		n := &right
-		if left.Data < right.Data {
+		if left.Data <= right.Data {
 			n = &left
 		}
 		t.Next = *n
@@ -884,6 +592,7 @@ func recursiveMergeSortLeft(head *Node) *Node {
 
 	return h
 }
+
 func recursiveMergeSortRight(head *Node) *Node {
 	if head.Next == nil {
 		// single node list is sorted by definition
@@ -913,8 +622,10 @@ func recursiveMergeSortRight(head *Node) *Node {
 
 	// Set h, t variables so that the loop doing the merge
 	// does not have to have a "if h == nil" check every iteration.
+	// left holds the earlier nodes of the list, so taking
+	// from left on equal data values keeps the sort stable.
 	x := &right
-	if left.Data < right.Data {
+	if left.Data <= right.Data {
 		x = &left
 	}
 
@@ -922,11 +633,11 @@ func recursiveMergeSortRight(head *Node) *Node {
 	*x = (*x).Next
 
 	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
+	// node longer, but the "<=" check might take more from one list
 	// than the other. Have to check both for nil.
 	for left != nil && right != nil {
 		n := &right
-		if left.Data < right.Data {
+		if left.Data <= right.Data {
 			n = &left
 		}
 		t.Next = *n
@@ -947,11 +658,3 @@ func recursiveMergeSortRight(head *Node) *Node {
 
 	return h
 }
-
-func listSize(node *Node) int {
-	count := 0
-	for ; node != nil; node = node.Next {
-		count++
-	}
-	return count
-}
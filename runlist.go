@@ -4,28 +4,37 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"math/rand"
 	"os"
 	"time"
-	"unsafe"
+
+	"mergesort/listgen"
+	"mergesort/listsort"
+	"mergesort/prng"
 )
 
 // Node is an element of a linked list
-type Node struct {
-	Data uint
-	Next *Node
-}
+type Node = listsort.Node
 
 func main() {
 	addressOrderedList := flag.Bool("m", false, "create address-ordered list")
 	addressOrderedListBW := flag.Bool("M", false, "create reverse address-ordered list")
 	randomlyOrderedList := flag.Bool("r", false, "create randomly-memory-ordered list")
+	hugePages := flag.String("H", "", "allocate nodes from an mmap arena, madvise huge, nohuge or none")
 	countIncrement := flag.Int("i", 200000, "increment of list size")
 	countBegin := flag.Int("b", 1000, "beginning list size")
 	countUntil := flag.Int("u", 18000000, "sort lists up to this size")
+	prngName := flag.String("prng", "pcg", "pseudo-random number generator: "+prng.Names)
+	seed := flag.Uint64("seed", 0, "PRNG seed, 0 picks a seed from the time and process ID")
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano() | int64(os.Getpid()))
+	if *seed == 0 {
+		*seed = prng.Seed()
+	}
+	rng, err := prng.New(*prngName, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listgen.UseRand(rng)
 
 	if *addressOrderedList && *randomlyOrderedList {
 		log.Fatal("only one of -m and -r per run")
@@ -33,27 +42,32 @@ func main() {
 
 	hostname, _ := os.Hostname() // not going to fail
 	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	fmt.Printf("# %s PRNG, seed %d\n", *prngName, *seed)
 	fmt.Printf("# Start at %d nodes, end before %d nodes, increment %d\n",
 		*countBegin, *countUntil, *countIncrement)
 
-	var listCreation func(int) *Node
+	var listCreation func(int, bool) *Node
 	var listType string
 
 	switch {
 	case *addressOrderedList:
 		listType = "memory address"
-		listCreation = memoryOrderedList
+		listCreation = listgen.MemoryOrderedList
 	case *randomlyOrderedList:
 		listType = "randomly-addressed"
-		listCreation = randomAddressedList
+		listCreation = listgen.RandomAddressedList
 	case *addressOrderedListBW:
 		listType = "reverse memory address"
-		listCreation = memoryOrderedListBW
+		listCreation = listgen.MemoryOrderedListBW
 	default:
 		listType = "idomatic"
-		listCreation = randomValueList
+		listCreation = listgen.ReverseSortedList
 	}
 	fmt.Printf("# %s list ordering\n", listType)
+	if *hugePages != "" {
+		fmt.Printf("# nodes allocated from an mmap arena, madvise %s, transparent huge pages %s\n",
+			*hugePages, listgen.THPEnabled())
+	}
 
 	fmt.Println("# list length, mean ET to walk list, overall ET for 10 walks")
 
@@ -61,10 +75,18 @@ func main() {
 	for n := *countBegin; n < *countUntil; n += *countIncrement {
 		var total time.Duration
 		var looping time.Duration
+		var mmapArena *listgen.MmapArena
+		if *hugePages != "" {
+			var err error
+			if mmapArena, err = listgen.NewMmapArena(n, *hugePages); err != nil {
+				log.Fatal(err)
+			}
+			listgen.UseMmapArena(mmapArena)
+		}
 		var head *Node
 		for i := 0; i < 10; i++ {
 			// fresh, new list every iteration
-			head = listCreation(n)
+			head = listCreation(n, true)
 
 			listLength := 0
 			before := time.Now()
@@ -81,197 +103,19 @@ func main() {
 
 			elapsed = time.Since(before)
 			looping += elapsed
+
+			if mmapArena != nil {
+				mmapArena.Reset()
+			}
+		}
+		if mmapArena != nil {
+			head = nil
+			if err := mmapArena.Close(); err != nil {
+				log.Fatal(err)
+			}
 		}
 		total /= 10.0
 		fmt.Printf("%d\t%.04f\t%.04f\t%s\n", n, total.Seconds(), looping.Seconds(), time.Now().Format(time.RFC3339))
 	}
 	fmt.Printf("# end at %s after %s on %s\n", time.Now().Format(time.RFC3339), time.Since(beforeLoop), hostname)
 }
-
-func randomValueList(n int) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		head = &Node{
-			Data: uint(i),
-			Next: head,
-		}
-	}
-
-	return head
-}
-
-func randomAddressedList(n int) *Node {
-	return recursiveMergeSort(randomValueList(n))
-}
-
-func memoryOrderedList(n int) *Node {
-
-	head := &Node{}
-	head.Data = uint(uintptr(unsafe.Pointer(head)))
-	tail := head
-
-	// Append new *Node to end of list - this will create
-	// a list that has blocks of nodes in ascending address order
-	for i := 1; i < n; i++ {
-		nn := &Node{}
-		nn.Data = uint(uintptr(unsafe.Pointer(nn)))
-		tail.Next = nn
-		tail = tail.Next
-	}
-
-	// sort all nodes by address, so that even blocks of nodes are
-	// ordered by ascending address.
-	return recursiveMergeSort(head)
-}
-
-func memoryOrderedListBW(n int) *Node {
-
-	var head *Node
-
-	for i := 0; i < n; i++ {
-		nn := &Node{}
-		nn.Data = uint(uintptr(unsafe.Pointer(nn)))
-		nn.Next = head
-		head = nn
-	}
-
-	// sort all nodes by address, so that even blocks of nodes are
-	// ordered by descending address.
-	return recursiveMergeSortReversed(head)
-}
-
-func recursiveMergeSort(head *Node) *Node {
-	if head.Next == nil {
-		// single node list is sorted by definiton
-		return head
-	}
-
-	// because of recursion bottoming out at a 1-long-list,
-	// head points to a list of at least 2 elements.
-
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
-		}
-	}
-
-	right := *turtle
-	*turtle = nil
-
-	left := recursiveMergeSort(head)
-	right = recursiveMergeSort(right)
-
-	// Set h, t variables so that the loop doing the merge
-	// does not have to have a "if h == nil" check every iteration.
-	x := &right
-	if left.Data < right.Data {
-		x = &left
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
-	// than the other. Have to check both for nil.
-	for left != nil && right != nil {
-		var n *Node
-		if left.Data < right.Data {
-			n = left
-			left = left.Next
-		} else {
-			n = right
-			right = right.Next
-		}
-		t.Next = n
-		t = t.Next
-		// At the end of this for-loop, t.Next ends up being nil
-		// because of the left/right list splitting.
-	}
-
-	// Either left or right are nil. If left == nil,
-	// assigning nil to t.Next is no issue.
-	t.Next = left
-	if right != nil {
-		// but if right is nil, can't assign nil to t.Next,
-		// because left was non-nil.
-		t.Next = right
-	}
-
-	return h
-}
-
-func recursiveMergeSortReversed(head *Node) *Node {
-	if head.Next == nil {
-		// single node list is sorted by definiton
-		return head
-	}
-
-	// because of recursion bottoming out at a 1-long-list,
-	// head points to a list of at least 2 elements.
-
-	// Setting rabbit and turtle like this means we split an
-	// odd-length-list (head) into lists of length n (right)
-	// and n+1 (left).
-	rabbit, turtle := head.Next, &head
-
-	for rabbit != nil {
-		turtle = &(*turtle).Next
-		if rabbit = rabbit.Next; rabbit != nil {
-			rabbit = rabbit.Next
-		}
-	}
-
-	right := *turtle
-	*turtle = nil
-
-	left := recursiveMergeSortReversed(head)
-	right = recursiveMergeSortReversed(right)
-
-	// Set h, t variables so that the loop doing the merge
-	// does not have to have a "if h == nil" check every iteration.
-	x := &right
-	if left.Data > right.Data {
-		x = &left
-	}
-
-	h, t := *x, *x
-	*x = (*x).Next
-
-	// left and right are either equal in length, or right is one
-	// node longer, but the "<" check might take more from one list
-	// than the other. Have to check both for nil.
-	for left != nil && right != nil {
-		var n *Node
-		if left.Data > right.Data {
-			n = left
-			left = left.Next
-		} else {
-			n = right
-			right = right.Next
-		}
-		t.Next = n
-		t = t.Next
-		// At the end of this for-loop, t.Next ends up being nil
-		// because of the left/right list splitting.
-	}
-
-	// Either left or right are nil. If left == nil,
-	// assigning nil to t.Next is no issue.
-	t.Next = left
-	if right != nil {
-		// but if right is nil, can't assign nil to t.Next,
-		// because left was non-nil.
-		t.Next = right
-	}
-
-	return h
-}
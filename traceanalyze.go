@@ -0,0 +1,189 @@
+package main
+
+/*
+ * Read memory access traces that mergetest -M writes, and
+ * compute reuse distance and stride distributions of the
+ * node reads and writes in them
+ */
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/bits"
+	"os"
+	"slices"
+	"time"
+
+	"mergesort/memtrace"
+)
+
+func main() {
+	level := flag.Int("l", -1, "only use records of this merge level, -1 for all levels")
+	op := flag.String("o", "", "only use read or write records, empty for both")
+	granularity := flag.Int("g", 0, "bytes of memory one address covers, 64 for cache lines, 0 for the trace's node size")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] tracefile ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *op != "" && *op != memtrace.Read.String() && *op != memtrace.Write.String() {
+		log.Fatalf("-o %q isn't read or write\n", *op)
+	}
+	if *granularity < 0 {
+		log.Fatalf("-g has to be 0 or more\n")
+	}
+
+	hostname, _ := os.Hostname() // not going to fail
+	fmt.Printf("# %s on %s\n", time.Now().Format(time.RFC3339), hostname)
+	if *level >= 0 {
+		fmt.Printf("# merge level %d records only\n", *level)
+	}
+	if *op != "" {
+		fmt.Printf("# %s records only\n", *op)
+	}
+
+	for i, fileName := range flag.Args() {
+		if i > 0 {
+			// gnuplot "index" separator
+			fmt.Print("\n\n")
+		}
+		if err := analyze(fileName, *level, *op, *granularity); err != nil {
+			log.Fatalf("%s: %v\n", fileName, err)
+		}
+	}
+}
+
+// analyze prints the reuse distance and stride histograms
+// of the records in the trace file fileName, as two
+// gnuplot data sets.
+func analyze(fileName string, level int, op string, granularity int) error {
+	fin, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+
+	r, err := memtrace.NewReader(fin)
+	if err != nil {
+		return err
+	}
+	if granularity == 0 {
+		granularity = max(r.NodeSize, 1)
+	}
+
+	var records, used, firsts int64
+	var ops [2]int64
+	var levels [memtrace.MaxLevel + 1]int64
+	var reuse []int64
+	strides := make(map[int]int64)
+	reuses := memtrace.NewReuse()
+	var prev uintptr
+	havePrev := false
+
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		records++
+		ops[rec.Op]++
+		levels[rec.Level]++
+		if level >= 0 && rec.Level != level {
+			continue
+		}
+		if op != "" && rec.Op.String() != op {
+			continue
+		}
+		used++
+
+		if d := reuses.Access(rec.Addr / uintptr(granularity)); d < 0 {
+			firsts++
+		} else {
+			b := bits.Len(uint(d))
+			if b >= len(reuse) {
+				reuse = append(reuse, make([]int64, b+1-len(reuse))...)
+			}
+			reuse[b]++
+		}
+
+		if havePrev {
+			strides[strideBucket(int64(rec.Addr-prev)/int64(granularity))]++
+		}
+		prev, havePrev = rec.Addr, true
+	}
+
+	fmt.Printf("# %s: %d-byte nodes, %d records, %d reads, %d writes\n",
+		fileName, r.NodeSize, records, ops[memtrace.Read], ops[memtrace.Write])
+	for l, n := range levels {
+		if n > 0 {
+			fmt.Printf("# merge level %d: %d records\n", l, n)
+		}
+	}
+	fmt.Printf("# %d records used, %d distinct %d-byte addresses\n", used, reuses.Distinct(), granularity)
+
+	fmt.Printf("# reuse distance, distinct addresses accessed since the last access to the same address\n")
+	fmt.Printf("# %d first accesses, no reuse distance\n", firsts)
+	fmt.Printf("# from, to, accesses, fraction, cumulative fraction of all used records\n")
+	var cumulative int64
+	for b, n := range reuse {
+		lo, hi := bucketRange(b)
+		cumulative += n
+		fmt.Printf("%d\t%d\t%d\t%.06f\t%.06f\n", lo, hi, n, fraction(n, used), fraction(cumulative, used))
+	}
+
+	fmt.Print("\n\n")
+	fmt.Printf("# stride, %d-byte units from the previous used record's address\n", granularity)
+	fmt.Printf("# from, to, accesses, fraction\n")
+	buckets := make([]int, 0, len(strides))
+	for b := range strides {
+		buckets = append(buckets, b)
+	}
+	slices.Sort(buckets)
+	for _, b := range buckets {
+		lo, hi := bucketRange(b)
+		if b < 0 {
+			lo, hi = -hi, -lo
+		}
+		fmt.Printf("%d\t%d\t%d\t%.06f\n", lo, hi, strides[b], fraction(strides[b], used-1))
+	}
+
+	return nil
+}
+
+// strideBucket is 0 for a stride of 0, k for strides
+// from 2^(k-1) to 2^k - 1, and -k for the negatives of those.
+func strideBucket(stride int64) int {
+	if stride < 0 {
+		return -bits.Len64(uint64(-stride))
+	}
+	return bits.Len64(uint64(stride))
+}
+
+// bucketRange is the lowest and highest value in bucket b,
+// or in bucket -b for negative b
+func bucketRange(b int) (int64, int64) {
+	if b < 0 {
+		b = -b
+	}
+	if b == 0 {
+		return 0, 0
+	}
+	return 1 << (b - 1), 1<<b - 1
+}
+
+func fraction(n, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}